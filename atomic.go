@@ -10,14 +10,24 @@ type atomicInt64 struct {
 }
 
 func (ai *atomicInt64) Inc()           { ai.Add(1) }
+func (ai *atomicInt64) Dec()           { ai.Add(-1) }
 func (ai *atomicInt64) Add(v int64)    { atomic.AddInt64(&ai.int64, v) }
+func (ai *atomicInt64) Sub(v int64)    { ai.Add(-v) }
 func (ai *atomicInt64) Get() int64     { return atomic.LoadInt64(&ai.int64) }
 func (ai *atomicInt64) Update(v int64) { atomic.StoreInt64(&ai.int64, v) }
 
+func (ai *atomicInt64) Track() func() {
+	ai.Inc()
+
+	return ai.Dec
+}
+
 type atomicFloat64 struct {
 	uint64
 }
 
+func (af *atomicFloat64) Inc() { af.Add(1) }
+
 func (af *atomicFloat64) Get() float64 {
 	return math.Float64frombits(atomic.LoadUint64(&af.uint64))
 }
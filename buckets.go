@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LinearBuckets creates a HistogramOption configuring count buckets, each
+// width wide, the lowest upper bound being start.
+// An infinity bucket is automatically appended.
+func LinearBuckets(start, width float64, count int) HistogramOption {
+	if count < 1 {
+		panic("LinearBuckets needs a positive count")
+	}
+
+	if width <= 0 {
+		panic("LinearBuckets needs a positive width")
+	}
+
+	var cutoffs = make([]float64, count)
+
+	for i := range cutoffs {
+		cutoffs[i] = start + float64(i)*width
+	}
+
+	return StaticBuckets(cutoffs)
+}
+
+// ExponentialBuckets creates a HistogramOption configuring count buckets,
+// each upper bound being factor times the previous one, the lowest upper
+// bound being start.
+// An infinity bucket is automatically appended.
+func ExponentialBuckets(start, factor float64, count int) HistogramOption {
+	if count < 1 {
+		panic("ExponentialBuckets needs a positive count")
+	}
+
+	if start <= 0 {
+		panic("ExponentialBuckets needs a positive start value")
+	}
+
+	if factor <= 1 {
+		panic("ExponentialBuckets needs a factor greater than 1")
+	}
+
+	var cutoffs = make([]float64, count)
+
+	for i := range cutoffs {
+		cutoffs[i] = start
+		start *= factor
+	}
+
+	return StaticBuckets(cutoffs)
+}
+
+// ExponentialBucketsRange creates a HistogramOption configuring count
+// exponential buckets, the lowest upper bound being min and the highest max.
+// An infinity bucket is automatically appended.
+func ExponentialBucketsRange(min, max float64, count int) HistogramOption {
+	if count < 2 {
+		panic("ExponentialBucketsRange needs a count greater than 1")
+	}
+
+	if min <= 0 {
+		panic("ExponentialBucketsRange needs a positive min value")
+	}
+
+	if max <= min {
+		panic("ExponentialBucketsRange needs a max value greater than the min value")
+	}
+
+	var (
+		factor  = math.Pow(max/min, 1/float64(count-1))
+		cutoffs = make([]float64, count)
+	)
+
+	for i := range cutoffs {
+		cutoffs[i] = min * math.Pow(factor, float64(i))
+	}
+
+	cutoffs[count-1] = max
+
+	return StaticBuckets(cutoffs)
+}
+
+// DurationBuckets creates a HistogramOption configuring count exponential
+// buckets, expressed in seconds as recorded by Timer, the lowest upper bound
+// being min and the highest max.
+// An infinity bucket is automatically appended.
+func DurationBuckets(min, max time.Duration, count int) HistogramOption {
+	return ExponentialBucketsRange(min.Seconds(), max.Seconds(), count)
+}
+
+// validateCutoffs panics if the cutoffs are not finite or not strictly
+// increasing, a trailing infinity is tolerated and dropped.
+func validateCutoffs(cutoffs []float64) []float64 {
+	if l := len(cutoffs); l > 0 && math.IsInf(cutoffs[l-1], 1) {
+		cutoffs = cutoffs[:l-1]
+	}
+
+	for i, c := range cutoffs {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			panic(fmt.Sprintf("histogram cutoffs must be finite, has: %v", cutoffs))
+		}
+
+		if i > 0 && c <= cutoffs[i-1] {
+			panic(
+				fmt.Sprintf(
+					"histogram cutoffs must be sorted and deduplicated, has: %v",
+					cutoffs,
+				),
+			)
+		}
+	}
+
+	return cutoffs
+}
@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func histogramCutoffs(opt HistogramOption) []float64 {
+	var hv histogramVector
+
+	opt.applyHistogram(&hv)
+
+	return hv.cutoffs
+}
+
+func TestBuckets(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opt  HistogramOption
+		want []float64
+	}{
+		{
+			name: "static",
+			opt:  StaticBuckets([]float64{1, 2}),
+			want: []float64{1, 2, math.Inf(0)},
+		},
+		{
+			name: "static with trailing infinity",
+			opt:  StaticBuckets([]float64{1, math.Inf(0)}),
+			want: []float64{1, math.Inf(0)},
+		},
+		{
+			name: "linear",
+			opt:  LinearBuckets(1, 2, 3),
+			want: []float64{1, 3, 5, math.Inf(0)},
+		},
+		{
+			name: "exponential",
+			opt:  ExponentialBuckets(1, 10, 3),
+			want: []float64{1, 10, 100, math.Inf(0)},
+		},
+		{
+			name: "exponential range",
+			opt:  ExponentialBucketsRange(1, 100, 3),
+			want: []float64{1, 10, 100, math.Inf(0)},
+		},
+		{
+			name: "duration",
+			opt:  DurationBuckets(time.Millisecond, time.Second, 4),
+			want: []float64{.001, .01, .1, 1, math.Inf(0)},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := histogramCutoffs(tt.opt)
+
+			assert.Equal(t, len(tt.want), len(got))
+
+			for i, c := range tt.want {
+				assert.InEpsilon(t, c, got[i], 1e-9)
+			}
+		})
+	}
+}
+
+func TestBucketsPanic(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   func()
+	}{
+		{name: "unsorted", fn: func() { StaticBuckets([]float64{2, 1}) }},
+		{name: "duplicated", fn: func() { StaticBuckets([]float64{1, 1}) }},
+		{name: "nan", fn: func() { StaticBuckets([]float64{math.NaN()}) }},
+		{name: "infinity", fn: func() { StaticBuckets([]float64{math.Inf(-1), 1}) }},
+		{name: "linear count", fn: func() { LinearBuckets(1, 1, 0) }},
+		{name: "linear width", fn: func() { LinearBuckets(1, 0, 2) }},
+		{name: "exponential start", fn: func() { ExponentialBuckets(0, 2, 2) }},
+		{name: "exponential factor", fn: func() { ExponentialBuckets(1, 1, 2) }},
+		{name: "range count", fn: func() { ExponentialBucketsRange(1, 2, 1) }},
+		{name: "range bounds", fn: func() { ExponentialBucketsRange(2, 1, 3) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) { assert.Panics(t, tt.fn) })
+	}
+}
+
+func TestStaticBucketsDoesNotAlias(t *testing.T) {
+	var cutoffs = make([]float64, 2, 3)
+
+	cutoffs[0], cutoffs[1] = 1, 2
+
+	histogramCutoffs(StaticBuckets(cutoffs))
+
+	assert.Equal(t, []float64{1, 2, 0}, cutoffs[:3])
+}
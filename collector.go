@@ -1,6 +1,10 @@
 package stats
 
-import "io"
+import (
+	"io"
+	"math"
+	"strconv"
+)
 
 // Collector is the interface that metrics backends must implement to receive
 // and export metrics. Collectors are notified when new metrics are registered
@@ -19,3 +23,190 @@ type Collector interface {
 	// RegisterHistogram registers a histogram metric with the given name.
 	RegisterHistogram(string, HistogramVectorGetter)
 }
+
+// FloatCollector is an optional interface implemented by the collectors
+// natively supporting float counters and gauges.
+type FloatCollector interface {
+	// RegisterFloatCounter registers a float counter metric with the given name.
+	RegisterFloatCounter(string, Float64VectorGetter)
+
+	// RegisterFloatGauge registers a float gauge metric with the given name.
+	RegisterFloatGauge(string, Float64VectorGetter)
+}
+
+// RegisterFloatCounter registers the float counter to the collector. If the
+// collector does not implement FloatCollector, the counter is registered as
+// an int64 counter with its values rounded to the nearest integer.
+func RegisterFloatCounter(c Collector, n string, g Float64VectorGetter) {
+	if fc, ok := c.(FloatCollector); ok {
+		fc.RegisterFloatCounter(n, g)
+		return
+	}
+
+	c.RegisterCounter(n, roundingInt64VectorGetter{g})
+}
+
+// RegisterFloatGauge registers the float gauge to the collector. If the
+// collector does not implement FloatCollector, the gauge is registered as
+// an int64 gauge with its values rounded to the nearest integer.
+func RegisterFloatGauge(c Collector, n string, g Float64VectorGetter) {
+	if fc, ok := c.(FloatCollector); ok {
+		fc.RegisterFloatGauge(n, g)
+		return
+	}
+
+	c.RegisterGauge(n, roundingInt64VectorGetter{g})
+}
+
+// SummaryCollector is an optional interface implemented by the collectors
+// natively supporting summaries.
+type SummaryCollector interface {
+	// RegisterSummary registers a summary metric with the given name.
+	RegisterSummary(string, SummaryVectorGetter)
+}
+
+// RegisterSummary registers the summary to the collector. If the collector
+// does not implement SummaryCollector, the summary is registered as a float
+// gauge holding the quantiles under an additional "quantile" label, a
+// "<name>_count" counter and a "<name>_sum" float counter.
+func RegisterSummary(c Collector, n string, g SummaryVectorGetter) {
+	if sc, ok := c.(SummaryCollector); ok {
+		sc.RegisterSummary(n, g)
+		return
+	}
+
+	RegisterFloatGauge(c, n, summaryQuantilesGetter{g})
+	c.RegisterCounter(joinStrings(n, "count"), summaryCountGetter{g})
+	RegisterFloatCounter(c, joinStrings(n, "sum"), summarySumGetter{g})
+}
+
+// Unregisterer is an optional interface implemented by the collectors able to
+// forget the getters registered by a root scope, see Detach.
+type Unregisterer interface {
+	// Unregister removes the getter registered under the given name, the
+	// getter is compared with == to the registered ones.
+	Unregister(string, interface{})
+}
+
+// Unregister removes the getter registered under the given name from the
+// collector, undoing the fallbacks of RegisterFloatCounter, RegisterFloatGauge
+// and RegisterSummary. It does nothing if the collector does not implement
+// Unregisterer.
+func Unregister(c Collector, n string, g interface{}) {
+	u, ok := c.(Unregisterer)
+
+	if !ok {
+		return
+	}
+
+	switch g := g.(type) {
+	case SummaryVectorGetter:
+		if _, ok := c.(SummaryCollector); !ok {
+			Unregister(c, n, summaryQuantilesGetter{g})
+			u.Unregister(joinStrings(n, "count"), summaryCountGetter{g})
+			Unregister(c, joinStrings(n, "sum"), summarySumGetter{g})
+
+			return
+		}
+	case Float64VectorGetter:
+		if _, ok := c.(FloatCollector); !ok {
+			u.Unregister(n, roundingInt64VectorGetter{g})
+			return
+		}
+	}
+
+	u.Unregister(n, g)
+}
+
+type summaryQuantilesGetter struct {
+	g SummaryVectorGetter
+}
+
+func (g summaryQuantilesGetter) Labels() []string {
+	return append(g.g.Labels()[:len(g.g.Labels()):len(g.g.Labels())], "quantile")
+}
+
+func (g summaryQuantilesGetter) Metadata() Metadata { return GetMetadata(g.g) }
+
+func (g summaryQuantilesGetter) Get() []*Float64Value {
+	var res []*Float64Value
+
+	for _, v := range g.g.Get() {
+		for _, q := range v.Quantiles {
+			tags := make(map[string]string, len(v.Tags)+1)
+
+			for k, v := range v.Tags {
+				tags[k] = v
+			}
+
+			tags["quantile"] = strconv.FormatFloat(q.Quantile, 'g', -1, 64)
+
+			res = append(res, &Float64Value{Tags: tags, Value: q.Value})
+		}
+	}
+
+	return res
+}
+
+type summaryCountGetter struct {
+	SummaryVectorGetter
+}
+
+func (g summaryCountGetter) Metadata() Metadata {
+	return GetMetadata(g.SummaryVectorGetter)
+}
+
+func (g summaryCountGetter) Get() []*Int64Value {
+	var (
+		vs  = g.SummaryVectorGetter.Get()
+		res = make([]*Int64Value, len(vs))
+	)
+
+	for i, v := range vs {
+		res[i] = &Int64Value{Tags: v.Tags, Value: v.Count}
+	}
+
+	return res
+}
+
+type summarySumGetter struct {
+	SummaryVectorGetter
+}
+
+func (g summarySumGetter) Metadata() Metadata {
+	return GetMetadata(g.SummaryVectorGetter)
+}
+
+func (g summarySumGetter) Get() []*Float64Value {
+	var (
+		vs  = g.SummaryVectorGetter.Get()
+		res = make([]*Float64Value, len(vs))
+	)
+
+	for i, v := range vs {
+		res[i] = &Float64Value{Tags: v.Tags, Value: v.Sum}
+	}
+
+	return res
+}
+
+type roundingInt64VectorGetter struct {
+	Float64VectorGetter
+}
+
+func (g roundingInt64VectorGetter) Metadata() Metadata {
+	return GetMetadata(g.Float64VectorGetter)
+}
+
+func (g roundingInt64VectorGetter) Get() []*Int64Value {
+	var (
+		vs  = g.Float64VectorGetter.Get()
+		res = make([]*Int64Value, len(vs))
+	)
+
+	for i, v := range vs {
+		res[i] = &Int64Value{Tags: v.Tags, Value: int64(math.Round(v.Value))}
+	}
+
+	return res
+}
@@ -6,6 +6,20 @@ type CounterVector interface {
 	// WithLabels returns a Counter with the specified label values.
 	// The number of values must match the number of labels defined for this vector.
 	WithLabels(...string) Counter
+
+	// Bind returns the Counter of the specified label values like WithLabels,
+	// and pins its series so it is never evicted by WithTTL. The Counter is
+	// meant to be kept and reused instead of looking the series up again.
+	Bind(...string) Counter
+
+	// Delete removes the counter with the specified label values, it returns
+	// false if no such counter exists. The counters previously returned by
+	// WithLabels for these values are detached from the vector.
+	Delete(...string) bool
+
+	// Reset removes all the counters of the vector, including the ones
+	// created from other scopes registering the same name.
+	Reset()
 }
 
 // Counter represents a monotonically increasing metric.
@@ -28,7 +42,11 @@ type counterVector struct {
 }
 
 func (cv counterVector) WithLabels(ls ...string) Counter {
-	return cv.fetchValue(ls)
+	return cv.entity(ls).(Counter)
+}
+
+func (cv counterVector) Bind(ls ...string) Counter {
+	return cv.bind(ls).(Counter)
 }
 
 type partialCounterVector struct {
@@ -37,9 +55,19 @@ type partialCounterVector struct {
 }
 
 func (pcv partialCounterVector) WithLabels(ls ...string) Counter {
-	return pcv.cv.WithLabels(append(pcv.vs, ls...)...)
+	return withLabelValues(pcv.vs, ls, pcv.cv.WithLabels)
+}
+
+func (pcv partialCounterVector) Bind(ls ...string) Counter {
+	return withLabelValues(pcv.vs, ls, pcv.cv.Bind)
+}
+
+func (pcv partialCounterVector) Delete(ls ...string) bool {
+	return withLabelValues(pcv.vs, ls, pcv.cv.Delete)
 }
 
+func (pcv partialCounterVector) Reset() { pcv.cv.Reset() }
+
 type reorderCounterVector struct {
 	cv CounterVector
 	labelOrderer
@@ -49,6 +77,16 @@ func (rcv reorderCounterVector) WithLabels(ls ...string) Counter {
 	return rcv.cv.WithLabels(rcv.order(ls)...)
 }
 
+func (rcv reorderCounterVector) Bind(ls ...string) Counter {
+	return rcv.cv.Bind(rcv.order(ls)...)
+}
+
+func (rcv reorderCounterVector) Delete(ls ...string) bool {
+	return rcv.cv.Delete(rcv.order(ls)...)
+}
+
+func (rcv reorderCounterVector) Reset() { rcv.cv.Reset() }
+
 var (
 	// NoopCounter is a counter that discards all operations.
 	NoopCounter Counter = noopCounter{}
@@ -66,3 +104,7 @@ func (noopCounter) Get() int64 { return 0 }
 type noopCounterVector struct{}
 
 func (noopCounterVector) WithLabels(...string) Counter { return noopCounter{} }
+
+func (noopCounterVector) Bind(...string) Counter { return noopCounter{} }
+func (noopCounterVector) Delete(...string) bool  { return false }
+func (noopCounterVector) Reset()                 {}
@@ -2,6 +2,7 @@ package stats
 
 import (
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -202,6 +203,42 @@ func TestCounter(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "striped counter",
+			mutate: func(s Scope) {
+				var (
+					cv = s.CounterVector("foo", []string{"bar"}, Striped())
+					wg sync.WaitGroup
+				)
+
+				for i := 0; i < 8; i++ {
+					wg.Add(1)
+
+					go func() {
+						defer wg.Done()
+
+						for j := 0; j < 100; j++ {
+							cv.WithLabels("buz").Inc()
+						}
+					}()
+				}
+
+				wg.Wait()
+
+				cv.WithLabels("buz").Add(3)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"bar": "buz"},
+							Value:  803,
+						},
+					},
+				},
+			),
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewStaticCollector()
@@ -244,6 +281,19 @@ func BenchmarkCounterInc(b *testing.B) {
 	}
 }
 
+// benchmarkLabelValues returns the label values of n series, the series are
+// created beforehand so the keys of the vector are not all small integers.
+func benchmarkLabelValues(cv CounterVector, n int) [][]string {
+	var vs = make([][]string, n)
+
+	for i := range vs {
+		vs[i] = []string{"GET", "/api/users/" + strconv.Itoa(i)}
+		cv.WithLabels(vs[i]...)
+	}
+
+	return vs
+}
+
 func BenchmarkVectorCounter(b *testing.B) {
 	c := RootScope(NewStaticCollector()).CounterVector(
 		"foo",
@@ -256,3 +306,97 @@ func BenchmarkVectorCounter(b *testing.B) {
 		c.WithLabels("foo", "bar").Inc()
 	}
 }
+
+func BenchmarkVectorCounterSeries(b *testing.B) {
+	for _, bc := range []struct {
+		name  string
+		scope func(Scope) Scope
+	}{
+		{name: "root", scope: func(s Scope) Scope { return s }},
+		{
+			name: "scoped",
+			scope: func(s Scope) Scope {
+				return s.Scope("http", map[string]string{"service": "api"})
+			},
+		},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var (
+				cv = bc.scope(RootScope(NewStaticCollector())).CounterVector(
+					"requests",
+					[]string{"method", "path"},
+				)
+				vs = benchmarkLabelValues(cv, 1024)
+			)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				cv.WithLabels(vs[i%len(vs)]...).Inc()
+			}
+		})
+	}
+}
+
+func BenchmarkVectorCounterParallel(b *testing.B) {
+	var (
+		cv = RootScope(NewStaticCollector()).CounterVector(
+			"requests",
+			[]string{"method", "path"},
+		)
+		vs = benchmarkLabelValues(cv, 1024)
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cv.WithLabels(vs[i%len(vs)]...).Inc()
+		}
+	})
+}
+
+func BenchmarkVectorCounterBind(b *testing.B) {
+	c := RootScope(NewStaticCollector()).CounterVector(
+		"foo",
+		[]string{"bar", "buz"},
+	).Bind("foo", "bar")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Inc()
+		}
+	})
+}
+
+// BenchmarkCounterIncParallel compares the counters under contention, it only
+// shows the benefit of striping with several processors, e.g. with
+// -cpu 1,8,32.
+func BenchmarkCounterIncParallel(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []MetricOption
+	}{
+		{name: "atomic"},
+		{name: "striped", opts: []MetricOption{Striped()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c := RootScope(NewStaticCollector()).Counter("foo", bc.opts...)
+
+			b.SetParallelism(4)
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Inc()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package stats
+
+import "fmt"
+
+// ConflictKind is the property on which a metric conflicts with the metric
+// already registered under the same name.
+type ConflictKind int
+
+const (
+	// TypeConflict is reported when a metric of another type, or a counter
+	// instead of a gauge, is already registered under the same name.
+	TypeConflict ConflictKind = iota + 1
+
+	// LabelsConflict is reported when the metric is already registered with
+	// different labels.
+	LabelsConflict
+
+	// CutoffsConflict is reported when the histogram is already registered
+	// with different cutoffs.
+	CutoffsConflict
+
+	// ObjectivesConflict is reported when the summary is already registered
+	// with different objectives.
+	ObjectivesConflict
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case TypeConflict:
+		return "type"
+	case LabelsConflict:
+		return "labels"
+	case CutoffsConflict:
+		return "cutoffs"
+	case ObjectivesConflict:
+		return "objectives"
+	}
+
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// ConflictError is the error reported when a metric cannot be registered
+// because it conflicts with a metric already registered under the same name,
+// Has is the value of the metric being registered and Want the one of the
+// registered metric.
+type ConflictError struct {
+	Name string
+	Kind ConflictKind
+
+	Has  interface{}
+	Want interface{}
+}
+
+func (ce *ConflictError) Error() string {
+	return fmt.Sprintf(
+		"%s: metric already registered with different %s, has: %v, want %v",
+		ce.Name,
+		ce.Kind,
+		ce.Has,
+		ce.Want,
+	)
+}
+
+// RegistrationError is the error reported when the collector rejects a
+// metric for another reason than a conflict with a metric of the collector,
+// such as a metric registered to the underlying registry by another party.
+type RegistrationError struct {
+	Name string
+	Err  error
+}
+
+func (re *RegistrationError) Error() string {
+	return fmt.Sprintf("%s: metric rejected by the collector: %v", re.Name, re.Err)
+}
+
+func (re *RegistrationError) Unwrap() error { return re.Err }
+
+// LabelCountError is the error reported when a callback of a func vector
+// returns a number of label values not matching the labels of the vector,
+// Values holds the label values of the scope followed by the ones returned
+// by the callback.
+type LabelCountError struct {
+	Name   string
+	Labels []string
+	Values []string
+}
+
+func (lce *LabelCountError) Error() string {
+	return fmt.Sprintf(
+		"%s: not the correct number of label values, labels: %v, values: %v",
+		lce.Name,
+		lce.Labels,
+		lce.Values,
+	)
+}
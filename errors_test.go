@@ -0,0 +1,144 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithErrorHandler(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		mutate func(Scope)
+		want   []error
+	}{
+		{
+			name: "no conflict",
+			mutate: func(s Scope) {
+				s.CounterVector("foo", []string{"a", "b"}).WithLabels("1", "2").Inc()
+				s.CounterVector("foo", []string{"b", "a"}).WithLabels("2", "1").Inc()
+			},
+		},
+		{
+			name: "type conflict",
+			mutate: func(s Scope) {
+				s.Counter("foo").Inc()
+				s.Gauge("foo").Update(2)
+				s.GaugeFunc("foo", func() int64 { return 3 })
+			},
+			want: []error{
+				&ConflictError{Name: "foo", Kind: TypeConflict, Has: "gauge", Want: "counter"},
+				&ConflictError{Name: "foo", Kind: TypeConflict, Has: "gauge func", Want: "counter"},
+			},
+		},
+		{
+			name: "labels conflict",
+			mutate: func(s Scope) {
+				s.Counter("foo").Inc()
+				s.CounterVector("foo", []string{"a"}).WithLabels("1").Inc()
+				s.Scope("", map[string]string{"a": "1"}).Counter("foo").Inc()
+			},
+			want: []error{
+				&ConflictError{
+					Name: "foo",
+					Kind: LabelsConflict,
+					Has:  []string{"a"},
+					Want: []string{},
+				},
+				&ConflictError{
+					Name: "foo",
+					Kind: LabelsConflict,
+					Has:  []string{"a"},
+					Want: []string{},
+				},
+			},
+		},
+		{
+			name: "cutoffs conflict",
+			mutate: func(s Scope) {
+				s.Histogram("bar", StaticBuckets([]float64{1})).Record(1)
+				s.Histogram("bar", StaticBuckets([]float64{2})).Record(1)
+			},
+			want: []error{
+				&ConflictError{
+					Name: "bar",
+					Kind: CutoffsConflict,
+					Has:  []float64{2, math.Inf(1)},
+					Want: []float64{1, math.Inf(1)},
+				},
+			},
+		},
+		{
+			name: "derived names conflict",
+			mutate: func(s Scope) {
+				s.Histogram("foo", TrackExtrema(time.Minute)).Record(1)
+				s.Gauge("foo_min").Update(1)
+				s.Gauge("bar_max").Update(1)
+				s.Histogram("bar", TrackExtrema(time.Minute)).Record(1)
+				s.Summary("biz").Record(1)
+				s.Counter("biz_count").Inc()
+				s.Histogram(
+					"buz",
+					ObservationRange(0, 1),
+					OnInvalidObservation(CountInvalidObservations),
+				).Record(1)
+				s.Counter("buz" + InvalidObservationsSuffix).Inc()
+			},
+			want: []error{
+				&ConflictError{Name: "foo_min", Kind: TypeConflict, Has: "gauge", Want: "histogram"},
+				&ConflictError{Name: "bar_max", Kind: TypeConflict, Has: "histogram", Want: "gauge"},
+				&ConflictError{Name: "biz_count", Kind: TypeConflict, Has: "counter", Want: "summary"},
+				&ConflictError{
+					Name: "buz_invalid_observations",
+					Kind: TypeConflict,
+					Has:  "counter",
+					Want: "histogram",
+				},
+			},
+		},
+		{
+			name: "objectives conflict",
+			mutate: func(s Scope) {
+				s.Summary("biz", SummaryObjectives(map[float64]float64{.5: .05})).Record(1)
+				s.Summary("biz", SummaryObjectives(map[float64]float64{.5: .05})).Record(1)
+				s.Summary("biz", SummaryObjectives(map[float64]float64{.9: .01})).Record(1)
+			},
+			want: []error{
+				&ConflictError{
+					Name: "biz",
+					Kind: ObjectivesConflict,
+					Has:  map[float64]float64{.9: .01},
+					Want: map[float64]float64{.5: .05},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				errs []error
+
+				s = RootScope(
+					NewStaticCollector(),
+					WithErrorHandler(func(err error) { errs = append(errs, err) }),
+				)
+			)
+
+			assert.NotPanics(t, func() { tt.mutate(s) })
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
+
+func TestConflictPanics(t *testing.T) {
+	s := RootScope(NewStaticCollector())
+
+	s.Counter("foo")
+
+	assert.PanicsWithError(
+		t,
+		"foo: metric already registered with different type, has: gauge, want counter",
+		func() { s.Gauge("foo") },
+	)
+}
@@ -26,11 +26,15 @@ type int64Wrapper struct {
 }
 
 func (cw int64Wrapper) String() string {
+	md := stats.GetMetadata(cw.Int64VectorGetter)
+
 	return serializeJSON(
 		struct {
 			Type  string
+			Help  string `json:",omitempty"`
+			Unit  string `json:",omitempty"`
 			Value []*stats.Int64Value
-		}{Type: cw.vType, Value: cw.Get()},
+		}{Type: cw.vType, Help: md.Help, Unit: md.Unit, Value: cw.Get()},
 	)
 }
 
@@ -42,12 +46,49 @@ func (c *Collector) RegisterGauge(n string, g stats.Int64VectorGetter) {
 	expvar.Publish(n, int64Wrapper{Int64VectorGetter: g, vType: "gauge"})
 }
 
+type float64Wrapper struct {
+	stats.Float64VectorGetter
+
+	vType string
+}
+
+func (fw float64Wrapper) String() string {
+	var (
+		vs = fw.Get()
+		md = stats.GetMetadata(fw.Float64VectorGetter)
+	)
+
+	for _, v := range vs {
+		v.Value = finiteFloat(v.Value)
+	}
+
+	return serializeJSON(
+		struct {
+			Type  string
+			Help  string `json:",omitempty"`
+			Unit  string `json:",omitempty"`
+			Value []*stats.Float64Value
+		}{Type: fw.vType, Help: md.Help, Unit: md.Unit, Value: vs},
+	)
+}
+
+func (c *Collector) RegisterFloatCounter(n string, g stats.Float64VectorGetter) {
+	expvar.Publish(n, float64Wrapper{Float64VectorGetter: g, vType: "counter"})
+}
+
+func (c *Collector) RegisterFloatGauge(n string, g stats.Float64VectorGetter) {
+	expvar.Publish(n, float64Wrapper{Float64VectorGetter: g, vType: "gauge"})
+}
+
 type histogramWrapper struct {
 	stats.HistogramVectorGetter
 }
 
 func (hw histogramWrapper) String() string {
-	vs := hw.Get()
+	var (
+		vs = hw.Get()
+		md = stats.GetMetadata(hw.HistogramVectorGetter)
+	)
 
 	for _, v := range vs {
 		bs := make([]stats.Bucket, len(v.Buckets))
@@ -61,13 +102,23 @@ func (hw histogramWrapper) String() string {
 		}
 
 		v.Buckets = bs
+		v.Sum = finiteFloat(v.Sum)
+
+		if e := v.Extrema; e != nil {
+			v.Extrema = &stats.HistogramExtrema{
+				Min: finiteFloat(e.Min),
+				Max: finiteFloat(e.Max),
+			}
+		}
 	}
 
 	return serializeJSON(
 		struct {
 			Type  string
+			Help  string `json:",omitempty"`
+			Unit  string `json:",omitempty"`
 			Value []*stats.HistogramValue
-		}{Type: "histogram", Value: vs},
+		}{Type: "histogram", Help: md.Help, Unit: md.Unit, Value: vs},
 	)
 }
 
@@ -75,10 +126,58 @@ func (c *Collector) RegisterHistogram(n string, g stats.HistogramVectorGetter) {
 	expvar.Publish(n, histogramWrapper{HistogramVectorGetter: g})
 }
 
+type summaryWrapper struct {
+	stats.SummaryVectorGetter
+}
+
+func (sw summaryWrapper) String() string {
+	var (
+		vs = sw.Get()
+		md = stats.GetMetadata(sw.SummaryVectorGetter)
+	)
+
+	for _, v := range vs {
+		qs := make([]stats.Quantile, len(v.Quantiles))
+
+		for i, q := range v.Quantiles {
+			q.Value = finiteFloat(q.Value)
+			qs[i] = q
+		}
+
+		v.Quantiles = qs
+		v.Sum = finiteFloat(v.Sum)
+	}
+
+	return serializeJSON(
+		struct {
+			Type  string
+			Help  string `json:",omitempty"`
+			Unit  string `json:",omitempty"`
+			Value []*stats.SummaryValue
+		}{Type: "summary", Help: md.Help, Unit: md.Unit, Value: vs},
+	)
+}
+
+func (c *Collector) RegisterSummary(n string, g stats.SummaryVectorGetter) {
+	expvar.Publish(n, summaryWrapper{SummaryVectorGetter: g})
+}
+
+// finiteFloat replaces NaN and infinite values, which encoding/json refuses
+// to encode, by 0.
+func finiteFloat(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+
+	return f
+}
+
 func serializeJSON(payload interface{}) string {
 	var buf bytes.Buffer
 
-	json.NewEncoder(&buf).Encode(payload)
+	if err := json.NewEncoder(&buf).Encode(payload); err != nil {
+		return "null"
+	}
 
 	return buf.String()
 }
@@ -2,6 +2,7 @@ package expvar
 
 import (
 	"expvar"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +48,45 @@ func TestPublish(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "simple float gauge",
+			mutate: func(s stats.Scope) {
+				s.FloatGauge("fiz").Update(.37)
+			},
+			asserMap: func(t *testing.T, res map[string]string) {
+				assert.Equal(
+					t,
+					"{\"Type\":\"gauge\",\"Value\":[{\"Tags\":{},\"Value\":0.37}]}\n",
+					res["fiz"],
+				)
+			},
+		},
+		{
+			name: "non finite float gauge",
+			mutate: func(s stats.Scope) {
+				s.FloatGauge("fez").Update(math.NaN())
+			},
+			asserMap: func(t *testing.T, res map[string]string) {
+				assert.Equal(
+					t,
+					"{\"Type\":\"gauge\",\"Value\":[{\"Tags\":{},\"Value\":0}]}\n",
+					res["fez"],
+				)
+			},
+		},
+		{
+			name: "counter with metadata",
+			mutate: func(s stats.Scope) {
+				s.Counter("fuz", stats.WithHelp("fuz help"), stats.WithUnit("bytes")).Add(37)
+			},
+			asserMap: func(t *testing.T, res map[string]string) {
+				assert.Equal(
+					t,
+					"{\"Type\":\"counter\",\"Help\":\"fuz help\",\"Unit\":\"bytes\",\"Value\":[{\"Tags\":{},\"Value\":37}]}\n",
+					res["fuz"],
+				)
+			},
+		},
 		{
 			name: "simple histogram",
 			mutate: func(s stats.Scope) {
@@ -60,6 +100,55 @@ func TestPublish(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "histogram with extrema",
+			mutate: func(s stats.Scope) {
+				h := s.Histogram("boz", stats.StaticBuckets(nil), stats.TrackExtrema(0))
+
+				h.Record(.37)
+				h.Record(.12)
+			},
+			asserMap: func(t *testing.T, res map[string]string) {
+				assert.Equal(
+					t,
+					"{\"Type\":\"histogram\",\"Value\":[{\"Tags\":{},\"Count\":2,\"Sum\":0.49,\"Buckets\":[{\"Count\":2,\"UpperBound\":0}],\"Extrema\":{\"Min\":0.12,\"Max\":0.37}}]}\n",
+					res["boz"],
+				)
+			},
+		},
+		{
+			name: "histogram with non finite observations",
+			mutate: func(s stats.Scope) {
+				h := s.Histogram("bez", stats.StaticBuckets(nil), stats.TrackExtrema(0))
+
+				h.Record(math.Inf(1))
+				h.Record(math.Inf(-1))
+			},
+			asserMap: func(t *testing.T, res map[string]string) {
+				assert.Equal(
+					t,
+					"{\"Type\":\"histogram\",\"Value\":[{\"Tags\":{},\"Count\":2,\"Sum\":0,\"Buckets\":[{\"Count\":2,\"UpperBound\":0}],\"Extrema\":{\"Min\":0,\"Max\":0}}]}\n",
+					res["bez"],
+				)
+			},
+		},
+		{
+			name: "simple summary",
+			mutate: func(s stats.Scope) {
+				s.Summary(
+					"biz",
+					stats.SummaryObjectives(map[float64]float64{.5: .05}),
+				).Record(.37)
+				s.Summary("baz", stats.SummaryObjectives(map[float64]float64{.5: .05}))
+			},
+			asserMap: func(t *testing.T, res map[string]string) {
+				assert.Equal(
+					t,
+					"{\"Type\":\"summary\",\"Value\":[{\"Tags\":{},\"Count\":1,\"Sum\":0.37,\"Quantiles\":[{\"Quantile\":0.5,\"Value\":0.37}]}]}\n",
+					res["biz"],
+				)
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mutate(stats.RootScope(NewCollector()))
@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// HistogramExtrema holds the smallest and the largest values observed by a
+// histogram over its extrema window.
+type HistogramExtrema struct {
+	Min float64
+	Max float64
+}
+
+// ExtremaGetter is implemented by the histogram vector getters whose values
+// carry the extrema of the observations, see TrackExtrema.
+type ExtremaGetter interface {
+	TracksExtrema() bool
+}
+
+// TracksExtrema returns whether the values of the getter carry the extrema of
+// the observations, it returns false if g does not implement ExtremaGetter.
+func TracksExtrema(g interface{}) bool {
+	if eg, ok := g.(ExtremaGetter); ok {
+		return eg.TracksExtrema()
+	}
+
+	return false
+}
+
+const defaultExtremaWindow = time.Minute
+
+// TrackExtrema creates a HistogramOption tracking the smallest and the largest
+// observed values of the histograms. The observations are accounted in
+// windows of the given duration, started by the first observation following
+// the end of the previous one, and the extrema cover the current and the
+// previous windows: a value stays visible for one to two windows whatever
+// the number of readers. The window should be at least the scrape interval,
+// a non-positive window defaults to one minute.
+func TrackExtrema(window time.Duration) HistogramOption {
+	if window <= 0 {
+		window = defaultExtremaWindow
+	}
+
+	return histogramOptionFunc(func(hv *histogramVector) {
+		hv.extrema = &extremaOptions{window: window}
+	})
+}
+
+type extremaOptions struct {
+	window time.Duration
+}
+
+type extremaWindow struct {
+	start int64
+
+	observed uint32
+	min      atomicFloat64
+	max      atomicFloat64
+}
+
+func newExtremaWindow(start int64) *extremaWindow {
+	var w = extremaWindow{start: start}
+
+	w.min.Update(math.Inf(1))
+	w.max.Update(math.Inf(-1))
+
+	return &w
+}
+
+func (w *extremaWindow) value() *HistogramExtrema {
+	if atomic.LoadUint32(&w.observed) == 0 {
+		return nil
+	}
+
+	return &HistogramExtrema{Min: w.min.Get(), Max: w.max.Get()}
+}
+
+// extremaWindows is swapped as a whole when the current window elapses, the
+// windows older than the previous one are dropped.
+type extremaWindows struct {
+	current  *extremaWindow
+	previous *extremaWindow
+}
+
+// histogramExtrema rotates the windows on the write path so the reads do not
+// alter them, an observation racing with the rotation may land in the
+// previous window.
+type histogramExtrema struct {
+	window    int64
+	timestamp func() int64
+
+	windows atomic.Pointer[extremaWindows]
+}
+
+func newHistogramExtrema(opts *extremaOptions, timestamp func() int64) *histogramExtrema {
+	he := histogramExtrema{window: int64(opts.window), timestamp: timestamp}
+	he.windows.Store(&extremaWindows{current: newExtremaWindow(timestamp())})
+
+	return &he
+}
+
+func updateFloat64(af *atomicFloat64, v float64, fn func(float64, float64) bool) {
+	for {
+		old := atomic.LoadUint64(&af.uint64)
+
+		if !fn(v, math.Float64frombits(old)) ||
+			atomic.CompareAndSwapUint64(&af.uint64, old, math.Float64bits(v)) {
+			return
+		}
+	}
+}
+
+func lessFloat64(x, y float64) bool    { return x < y }
+func greaterFloat64(x, y float64) bool { return x > y }
+
+// currentWindow returns the window the observations made at now belong to,
+// starting a new one if the current window elapsed.
+func (he *histogramExtrema) currentWindow(now int64) *extremaWindow {
+	for {
+		ws := he.windows.Load()
+
+		if now-ws.current.start < he.window {
+			return ws.current
+		}
+
+		nws := extremaWindows{current: newExtremaWindow(now)}
+
+		if now-ws.current.start < 2*he.window {
+			nws.previous = ws.current
+		}
+
+		if he.windows.CompareAndSwap(ws, &nws) {
+			return nws.current
+		}
+	}
+}
+
+// record accounts for observations ranging from minV to maxV.
+func (he *histogramExtrema) record(minV, maxV float64) {
+	w := he.currentWindow(he.timestamp())
+
+	updateFloat64(&w.min, minV, lessFloat64)
+	updateFloat64(&w.max, maxV, greaterFloat64)
+
+	atomic.StoreUint32(&w.observed, 1)
+}
+
+// value returns the extrema of the windows that elapsed less than a window
+// ago, it returns nil if nothing was observed within them.
+func (he *histogramExtrema) value() *HistogramExtrema {
+	var (
+		ws  = he.windows.Load()
+		now = he.timestamp()
+
+		res *HistogramExtrema
+	)
+
+	for _, w := range []*extremaWindow{ws.current, ws.previous} {
+		if w == nil || now-w.start >= 2*he.window {
+			continue
+		}
+
+		v := w.value()
+
+		switch {
+		case v == nil:
+			continue
+		case res == nil:
+			res = v
+		default:
+			res.Min = math.Min(res.Min, v.Min)
+			res.Max = math.Max(res.Max, v.Max)
+		}
+	}
+
+	return res
+}
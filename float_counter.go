@@ -0,0 +1,112 @@
+package stats
+
+// FloatCounterVector is a multi-dimensional float counter that creates float
+// counter instances with specific label values.
+type FloatCounterVector interface {
+	// WithLabels returns a FloatCounter with the specified label values.
+	// The number of values must match the number of labels defined for this vector.
+	WithLabels(...string) FloatCounter
+
+	// Bind returns the FloatCounter of the specified label values like WithLabels,
+	// and pins its series so it is never evicted by WithTTL. The FloatCounter is
+	// meant to be kept and reused instead of looking the series up again.
+	Bind(...string) FloatCounter
+
+	// Delete removes the float counter with the specified label values, it
+	// returns false if no such float counter exists. The float counters previously
+	// returned by WithLabels for these values are detached from the vector.
+	Delete(...string) bool
+
+	// Reset removes all the float counters of the vector, including the ones
+	// created from other scopes registering the same name.
+	Reset()
+}
+
+// FloatCounter represents a monotonically increasing float metric.
+// Float counters are used to track totals that are not integers, such as
+// the number of bytes transferred or the CPU seconds consumed.
+type FloatCounter interface {
+	// Inc increments the counter by 1.
+	Inc()
+
+	// Add increments the counter by the given value.
+	// The value should be non-negative.
+	Add(float64)
+
+	// Get returns the current value of the counter.
+	Get() float64
+}
+
+type floatCounterVector struct {
+	*atomicFloat64Vector
+}
+
+func (cv floatCounterVector) WithLabels(ls ...string) FloatCounter {
+	return cv.fetchValue(ls)
+}
+
+func (cv floatCounterVector) Bind(ls ...string) FloatCounter {
+	return cv.bind(ls).(*atomicFloat64)
+}
+
+type partialFloatCounterVector struct {
+	cv FloatCounterVector
+	vs []string
+}
+
+func (pcv partialFloatCounterVector) WithLabels(ls ...string) FloatCounter {
+	return withLabelValues(pcv.vs, ls, pcv.cv.WithLabels)
+}
+
+func (pcv partialFloatCounterVector) Bind(ls ...string) FloatCounter {
+	return withLabelValues(pcv.vs, ls, pcv.cv.Bind)
+}
+
+func (pcv partialFloatCounterVector) Delete(ls ...string) bool {
+	return withLabelValues(pcv.vs, ls, pcv.cv.Delete)
+}
+
+func (pcv partialFloatCounterVector) Reset() { pcv.cv.Reset() }
+
+type reorderFloatCounterVector struct {
+	cv FloatCounterVector
+	labelOrderer
+}
+
+func (rcv reorderFloatCounterVector) WithLabels(ls ...string) FloatCounter {
+	return rcv.cv.WithLabels(rcv.order(ls)...)
+}
+
+func (rcv reorderFloatCounterVector) Bind(ls ...string) FloatCounter {
+	return rcv.cv.Bind(rcv.order(ls)...)
+}
+
+func (rcv reorderFloatCounterVector) Delete(ls ...string) bool {
+	return rcv.cv.Delete(rcv.order(ls)...)
+}
+
+func (rcv reorderFloatCounterVector) Reset() { rcv.cv.Reset() }
+
+var (
+	// NoopFloatCounter is a float counter that discards all operations.
+	NoopFloatCounter FloatCounter = noopFloatCounter{}
+
+	// NoopFloatCounterVector is a float counter vector that returns noop float counters.
+	NoopFloatCounterVector FloatCounterVector = noopFloatCounterVector{}
+)
+
+type noopFloatCounter struct{}
+
+func (noopFloatCounter) Inc()         {}
+func (noopFloatCounter) Add(float64)  {}
+func (noopFloatCounter) Get() float64 { return 0 }
+
+type noopFloatCounterVector struct{}
+
+func (noopFloatCounterVector) WithLabels(...string) FloatCounter {
+	return noopFloatCounter{}
+}
+
+func (noopFloatCounterVector) Bind(...string) FloatCounter { return noopFloatCounter{} }
+func (noopFloatCounterVector) Delete(...string) bool       { return false }
+func (noopFloatCounterVector) Reset()                      {}
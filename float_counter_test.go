@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFloatCounter(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		mutate     func(Scope)
+		introspect func(*testing.T, Snapshot)
+	}{
+		{
+			name:   "simple float counter on root",
+			mutate: func(s Scope) { s.FloatCounter("foo").Add(.5) },
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatCounters: []Float64Snapshot{
+						{Name: "foo", Labels: map[string]string{}, Value: .5},
+					},
+				},
+			),
+		},
+		{
+			name: "labeled float counter on scope",
+			mutate: func(s Scope) {
+				cv := s.Scope("bar", map[string]string{"fiz": "buz"}).FloatCounterVector(
+					"foo",
+					[]string{"bar"},
+				)
+
+				cv.WithLabels("bua").Add(1.25)
+				cv.WithLabels("bua").Inc()
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatCounters: []Float64Snapshot{
+						{
+							Name:   "bar_foo",
+							Labels: map[string]string{"fiz": "buz", "bar": "bua"},
+							Value:  2.25,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "reordered labels",
+			mutate: func(s Scope) {
+				s.FloatCounterVector("foo", []string{"a", "b"}).WithLabels("1", "2").Add(1)
+				s.FloatCounterVector("foo", []string{"b", "a"}).WithLabels("2", "1").Add(1)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatCounters: []Float64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"a": "1", "b": "2"},
+							Value:  2,
+						},
+					},
+				},
+			),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStaticCollector()
+
+			tt.mutate(RootScope(c))
+			tt.introspect(t, c.Get())
+		})
+	}
+}
+
+type int64OnlyCollector struct {
+	Collector
+}
+
+func TestFloatCounterFallback(t *testing.T) {
+	c := NewStaticCollector()
+	s := RootScope(int64OnlyCollector{Collector: c})
+
+	s.FloatCounter("foo").Add(1.6)
+	s.FloatGauge("bar").Update(-2.2)
+
+	assert.Equal(
+		t,
+		Snapshot{
+			Counters: []Int64Snapshot{
+				{Name: "foo", Labels: map[string]string{}, Value: 2},
+			},
+			Gauges: []Int64Snapshot{
+				{Name: "bar", Labels: map[string]string{}, Value: -2},
+			},
+		},
+		c.Get(),
+	)
+}
+
+func TestFloatCounterNameClash(t *testing.T) {
+	s := RootScope(NewStaticCollector())
+
+	s.Counter("foo")
+
+	assert.Panics(t, func() { s.FloatCounter("foo") })
+}
+
+func BenchmarkFloatCounterInc(b *testing.B) {
+	c := RootScope(NewStaticCollector()).FloatCounter("foo")
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Add(.5)
+	}
+}
@@ -0,0 +1,105 @@
+package stats
+
+// FloatGaugeVector is a multi-dimensional float gauge that creates float gauge
+// instances with specific label values.
+type FloatGaugeVector interface {
+	// WithLabels returns a FloatGauge with the specified label values.
+	// The number of values must match the number of labels defined for this vector.
+	WithLabels(...string) FloatGauge
+
+	// Bind returns the FloatGauge of the specified label values like WithLabels,
+	// and pins its series so it is never evicted by WithTTL. The FloatGauge is
+	// meant to be kept and reused instead of looking the series up again.
+	Bind(...string) FloatGauge
+
+	// Delete removes the float gauge with the specified label values, it
+	// returns false if no such float gauge exists. The float gauges previously
+	// returned by WithLabels for these values are detached from the vector.
+	Delete(...string) bool
+
+	// Reset removes all the float gauges of the vector, including the ones
+	// created from other scopes registering the same name.
+	Reset()
+}
+
+// FloatGauge represents a float metric that can increase or decrease.
+// Float gauges are used to track current values that are not integers, such
+// as CPU ratios, temperatures or byte rates.
+type FloatGauge interface {
+	// Update sets the gauge to the given value.
+	Update(float64)
+
+	// Get returns the current value of the gauge.
+	Get() float64
+}
+
+type floatGaugeVector struct {
+	*atomicFloat64Vector
+}
+
+func (gv floatGaugeVector) WithLabels(ls ...string) FloatGauge {
+	return gv.fetchValue(ls)
+}
+
+func (gv floatGaugeVector) Bind(ls ...string) FloatGauge {
+	return gv.bind(ls).(*atomicFloat64)
+}
+
+type partialFloatGaugeVector struct {
+	gv FloatGaugeVector
+	vs []string
+}
+
+func (pgv partialFloatGaugeVector) WithLabels(ls ...string) FloatGauge {
+	return withLabelValues(pgv.vs, ls, pgv.gv.WithLabels)
+}
+
+func (pgv partialFloatGaugeVector) Bind(ls ...string) FloatGauge {
+	return withLabelValues(pgv.vs, ls, pgv.gv.Bind)
+}
+
+func (pgv partialFloatGaugeVector) Delete(ls ...string) bool {
+	return withLabelValues(pgv.vs, ls, pgv.gv.Delete)
+}
+
+func (pgv partialFloatGaugeVector) Reset() { pgv.gv.Reset() }
+
+type reorderFloatGaugeVector struct {
+	gv FloatGaugeVector
+	labelOrderer
+}
+
+func (rgv reorderFloatGaugeVector) WithLabels(ls ...string) FloatGauge {
+	return rgv.gv.WithLabels(rgv.order(ls)...)
+}
+
+func (rgv reorderFloatGaugeVector) Bind(ls ...string) FloatGauge {
+	return rgv.gv.Bind(rgv.order(ls)...)
+}
+
+func (rgv reorderFloatGaugeVector) Delete(ls ...string) bool {
+	return rgv.gv.Delete(rgv.order(ls)...)
+}
+
+func (rgv reorderFloatGaugeVector) Reset() { rgv.gv.Reset() }
+
+var (
+	// NoopFloatGauge is a float gauge that discards all operations.
+	NoopFloatGauge FloatGauge = noopFloatGauge{}
+
+	// NoopFloatGaugeVector is a float gauge vector that returns noop float gauges.
+	NoopFloatGaugeVector FloatGaugeVector = noopFloatGaugeVector{}
+)
+
+type noopFloatGauge struct{}
+
+func (noopFloatGauge) Update(float64) {}
+func (noopFloatGauge) Get() float64   { return 0 }
+
+type noopFloatGaugeVector struct{}
+
+func (noopFloatGaugeVector) WithLabels(...string) FloatGauge { return noopFloatGauge{} }
+
+func (noopFloatGaugeVector) Bind(...string) FloatGauge { return noopFloatGauge{} }
+func (noopFloatGaugeVector) Delete(...string) bool     { return false }
+func (noopFloatGaugeVector) Reset()                    {}
@@ -0,0 +1,73 @@
+package stats
+
+import "testing"
+
+func TestFloatGauge(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		mutate     func(Scope)
+		introspect func(*testing.T, Snapshot)
+	}{
+		{
+			name:   "simple float gauge on root",
+			mutate: func(s Scope) { s.FloatGauge("foo").Update(.75) },
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatGauges: []Float64Snapshot{
+						{Name: "foo", Labels: map[string]string{}, Value: .75},
+					},
+				},
+			),
+		},
+		{
+			name: "multiple use of labeled float gauge",
+			mutate: func(s Scope) {
+				g := s.FloatGaugeVector("foo", []string{"bar"})
+
+				g.WithLabels("bua").Update(1.5)
+				g.WithLabels("bua").Update(2.5)
+				g.WithLabels("buz").Update(-13.1)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatGauges: []Float64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"bar": "bua"},
+							Value:  2.5,
+						},
+						{
+							Name:   "foo",
+							Labels: map[string]string{"bar": "buz"},
+							Value:  -13.1,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "float gauge in incarnation scope",
+			mutate: func(s Scope) {
+				LocalIncarnationScope(s, "incarnation").FloatGauge("foo").Update(3.5)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatGauges: []Float64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"incarnation": "0"},
+							Value:  3.5,
+						},
+					},
+				},
+			),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStaticCollector()
+
+			tt.mutate(RootScope(c))
+			tt.introspect(t, c.Get())
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package stats
+
+import "sync"
+
+// LabeledInt64 associates label values, given in the order of the vector
+// labels, with a value. It is returned by the callbacks of the func vectors.
+type LabeledInt64 struct {
+	Values []string
+	Value  int64
+}
+
+func scalarInt64Func(fn func() int64) func() []LabeledInt64 {
+	return func() []LabeledInt64 { return []LabeledInt64{{Value: fn()}} }
+}
+
+type int64FuncSource struct {
+	vs []string
+	fn func() []LabeledInt64
+
+	labelOrderer labelOrderer
+}
+
+// funcInt64Vector is an Int64VectorGetter evaluating its callbacks at
+// collection time. Several callbacks can be registered under the same name,
+// typically from scopes holding different tags.
+type funcInt64Vector struct {
+	name     string
+	labels   []string
+	metadata Metadata
+
+	// onError reports the callback results not matching the labels, they
+	// are silently dropped if nil.
+	onError func(error)
+
+	mu      sync.RWMutex
+	sources []int64FuncSource
+}
+
+func (v *funcInt64Vector) Labels() []string   { return v.labels }
+func (v *funcInt64Vector) Metadata() Metadata { return v.metadata }
+
+func (v *funcInt64Vector) appendSource(s int64FuncSource) {
+	v.mu.Lock()
+	v.sources = append(v.sources, s)
+	v.mu.Unlock()
+}
+
+func (v *funcInt64Vector) handleError(err error) {
+	if v.onError != nil {
+		v.onError(err)
+	}
+}
+
+func (v *funcInt64Vector) Get() []*Int64Value {
+	var res []*Int64Value
+
+	v.mu.RLock()
+	sources := v.sources
+	v.mu.RUnlock()
+
+	for _, s := range sources {
+		for _, lv := range s.fn() {
+			vs := make([]string, 0, len(v.labels))
+			vs = append(append(vs, s.vs...), lv.Values...)
+
+			if len(vs) != len(v.labels) {
+				v.handleError(&LabelCountError{Name: v.name, Labels: v.labels, Values: vs})
+				continue
+			}
+
+			if s.labelOrderer != nil {
+				vs = s.labelOrderer.order(vs)
+			}
+
+			tags := make(map[string]string, len(v.labels))
+
+			for i, l := range v.labels {
+				tags[l] = vs[i]
+			}
+
+			res = append(res, &Int64Value{Tags: tags, Value: lv.Value})
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,199 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunc(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		mutate     func(Scope)
+		introspect func(*testing.T, Snapshot)
+	}{
+		{
+			name:   "gauge func on root",
+			mutate: func(s Scope) { s.GaugeFunc("foo", func() int64 { return 12 }) },
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{Name: "foo", Labels: map[string]string{}, Value: 12},
+					},
+				},
+			),
+		},
+		{
+			name: "counter func on scopes",
+			mutate: func(s Scope) {
+				s.Scope("db", map[string]string{"pool": "a"}).CounterFunc(
+					"queries",
+					func() int64 { return 1 },
+				)
+				s.Scope("db", map[string]string{"pool": "b"}).CounterFunc(
+					"queries",
+					func() int64 { return 2 },
+				)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{Name: "db_queries", Labels: map[string]string{"pool": "a"}, Value: 1},
+						{Name: "db_queries", Labels: map[string]string{"pool": "b"}, Value: 2},
+					},
+				},
+			),
+		},
+		{
+			name: "gauge vector func on scope",
+			mutate: func(s Scope) {
+				s.Scope("", map[string]string{"fiz": "buz"}).GaugeVectorFunc(
+					"queue_length",
+					[]string{"queue"},
+					func() []LabeledInt64 {
+						return []LabeledInt64{
+							{Values: []string{"high"}, Value: 3},
+							{Values: []string{"low"}, Value: 4},
+						}
+					},
+				)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{
+							Name:   "queue_length",
+							Labels: map[string]string{"fiz": "buz", "queue": "high"},
+							Value:  3,
+						},
+						{
+							Name:   "queue_length",
+							Labels: map[string]string{"fiz": "buz", "queue": "low"},
+							Value:  4,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "reordered labels",
+			mutate: func(s Scope) {
+				s.CounterVectorFunc(
+					"foo",
+					[]string{"a", "b"},
+					func() []LabeledInt64 {
+						return []LabeledInt64{{Values: []string{"1", "2"}, Value: 1}}
+					},
+				)
+				s.CounterVectorFunc(
+					"foo",
+					[]string{"b", "a"},
+					func() []LabeledInt64 {
+						return []LabeledInt64{{Values: []string{"3", "4"}, Value: 2}}
+					},
+				)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{Name: "foo", Labels: map[string]string{"a": "1", "b": "2"}, Value: 1},
+						{Name: "foo", Labels: map[string]string{"a": "4", "b": "3"}, Value: 2},
+					},
+				},
+			),
+		},
+		{
+			name: "incarnation gauge func",
+			mutate: func(s Scope) {
+				is := LocalIncarnationScope(s, "incarnation")
+
+				is.GaugeFunc("foo", func() int64 { return 1 })
+				is.GaugeFunc("foo", func() int64 { return 2 })
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{Name: "foo", Labels: map[string]string{"incarnation": "0"}, Value: 1},
+						{Name: "foo", Labels: map[string]string{"incarnation": "1"}, Value: 2},
+					},
+				},
+			),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStaticCollector()
+
+			tt.mutate(RootScope(c))
+			tt.introspect(t, c.Get())
+		})
+	}
+}
+
+func TestFuncLazyEvaluation(t *testing.T) {
+	var (
+		calls int64
+
+		c = NewStaticCollector()
+	)
+
+	RootScope(c).GaugeFunc("foo", func() int64 {
+		calls++
+		return calls
+	})
+
+	assert.Equal(t, int64(0), calls)
+	assert.Equal(t, int64(1), c.Get().Gauges[0].Value)
+	assert.Equal(t, int64(2), c.Get().Gauges[0].Value)
+}
+
+func TestFuncLabelCountMismatch(t *testing.T) {
+	var (
+		errs []error
+
+		fn = func() []LabeledInt64 {
+			return []LabeledInt64{
+				{Values: []string{"high"}, Value: 3},
+				{Values: []string{"invalid", "values"}, Value: 5},
+			}
+		}
+
+		c = NewStaticCollector()
+		s = RootScope(c, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	)
+
+	s.GaugeVectorFunc("foo", []string{"queue"}, fn)
+
+	assert.Equal(
+		t,
+		[]Int64Snapshot{{Name: "foo", Labels: map[string]string{"queue": "high"}, Value: 3}},
+		c.Get().Gauges,
+	)
+	assert.Equal(
+		t,
+		[]error{
+			&LabelCountError{
+				Name:   "foo",
+				Labels: []string{"queue"},
+				Values: []string{"invalid", "values"},
+			},
+		},
+		errs,
+	)
+
+	c = NewStaticCollector()
+	RootScope(c).GaugeVectorFunc("foo", []string{"queue"}, fn)
+
+	assert.Equal(
+		t,
+		[]Int64Snapshot{{Name: "foo", Labels: map[string]string{"queue": "high"}, Value: 3}},
+		c.Get().Gauges,
+	)
+}
+
+func TestFuncNameClash(t *testing.T) {
+	s := RootScope(NewStaticCollector())
+
+	s.Gauge("foo")
+
+	assert.Panics(t, func() { s.GaugeFunc("foo", func() int64 { return 0 }) })
+}
@@ -6,6 +6,20 @@ type GaugeVector interface {
 	// WithLabels returns a Gauge with the specified label values.
 	// The number of values must match the number of labels defined for this vector.
 	WithLabels(...string) Gauge
+
+	// Bind returns the Gauge of the specified label values like WithLabels,
+	// and pins its series so it is never evicted by WithTTL. The Gauge is
+	// meant to be kept and reused instead of looking the series up again.
+	Bind(...string) Gauge
+
+	// Delete removes the gauge with the specified label values, it returns
+	// false if no such gauge exists. The gauges previously returned by
+	// WithLabels for these values are detached from the vector.
+	Delete(...string) bool
+
+	// Reset removes all the gauges of the vector, including the ones
+	// created from other scopes registering the same name.
+	Reset()
 }
 
 // Gauge represents a metric that can increase or decrease.
@@ -15,6 +29,24 @@ type Gauge interface {
 	// Update sets the gauge to the given value.
 	Update(int64)
 
+	// Inc increments the gauge by 1.
+	Inc()
+
+	// Dec decrements the gauge by 1.
+	Dec()
+
+	// Add increments the gauge by the given value.
+	Add(int64)
+
+	// Sub decrements the gauge by the given value.
+	Sub(int64)
+
+	// Track increments the gauge by 1 and returns a function decrementing it,
+	// meant to be deferred:
+	//
+	//	defer inFlight.Track()()
+	Track() func()
+
 	// Get returns the current value of the gauge.
 	Get() int64
 }
@@ -27,15 +59,29 @@ func (gv gaugeVector) WithLabels(ls ...string) Gauge {
 	return gv.fetchValue(ls)
 }
 
+func (gv gaugeVector) Bind(ls ...string) Gauge {
+	return gv.bind(ls).(*atomicInt64)
+}
+
 type partialGaugeVector struct {
 	gv GaugeVector
 	vs []string
 }
 
 func (pgv partialGaugeVector) WithLabels(ls ...string) Gauge {
-	return pgv.gv.WithLabels(append(pgv.vs, ls...)...)
+	return withLabelValues(pgv.vs, ls, pgv.gv.WithLabels)
+}
+
+func (pgv partialGaugeVector) Bind(ls ...string) Gauge {
+	return withLabelValues(pgv.vs, ls, pgv.gv.Bind)
+}
+
+func (pgv partialGaugeVector) Delete(ls ...string) bool {
+	return withLabelValues(pgv.vs, ls, pgv.gv.Delete)
 }
 
+func (pgv partialGaugeVector) Reset() { pgv.gv.Reset() }
+
 type reorderGaugeVector struct {
 	gv GaugeVector
 	labelOrderer
@@ -45,6 +91,16 @@ func (rgv reorderGaugeVector) WithLabels(ls ...string) Gauge {
 	return rgv.gv.WithLabels(rgv.order(ls)...)
 }
 
+func (rgv reorderGaugeVector) Bind(ls ...string) Gauge {
+	return rgv.gv.Bind(rgv.order(ls)...)
+}
+
+func (rgv reorderGaugeVector) Delete(ls ...string) bool {
+	return rgv.gv.Delete(rgv.order(ls)...)
+}
+
+func (rgv reorderGaugeVector) Reset() { rgv.gv.Reset() }
+
 var (
 	// NoopGauge is a gauge that discards all operations.
 	NoopGauge Gauge = noopGauge{}
@@ -55,9 +111,20 @@ var (
 
 type noopGauge struct{}
 
-func (noopGauge) Update(int64) {}
-func (noopGauge) Get() int64   { return 0 }
+func noop() {}
+
+func (noopGauge) Update(int64)  {}
+func (noopGauge) Inc()          {}
+func (noopGauge) Dec()          {}
+func (noopGauge) Add(int64)     {}
+func (noopGauge) Sub(int64)     {}
+func (noopGauge) Track() func() { return noop }
+func (noopGauge) Get() int64    { return 0 }
 
 type noopGaugeVector struct{}
 
 func (noopGaugeVector) WithLabels(...string) Gauge { return noopGauge{} }
+
+func (noopGaugeVector) Bind(...string) Gauge  { return noopGauge{} }
+func (noopGaugeVector) Delete(...string) bool { return false }
+func (noopGaugeVector) Reset()                {}
@@ -1,6 +1,11 @@
 package stats
 
-import "testing"
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestGauge(t *testing.T) {
 	for _, tt := range []struct {
@@ -141,6 +146,50 @@ func TestGauge(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "relative updates",
+			mutate: func(s Scope) {
+				g := s.Gauge("foo")
+
+				g.Update(10)
+				g.Inc()
+				g.Add(5)
+				g.Dec()
+				g.Sub(3)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{},
+							Value:  12,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "tracked gauge",
+			mutate: func(s Scope) {
+				g := LocalIncarnationScope(s, "incarnation").Gauge("foo")
+
+				done := g.Track()
+				g.Track()
+				done()
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"incarnation": "0"},
+							Value:  1,
+						},
+					},
+				},
+			),
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewStaticCollector()
@@ -151,6 +200,31 @@ func TestGauge(t *testing.T) {
 	}
 }
 
+func TestGaugeConcurrentTrack(t *testing.T) {
+	var (
+		wg sync.WaitGroup
+
+		g = RootScope(NewStaticCollector()).Gauge("foo")
+	)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer g.Track()()
+
+			g.Add(2)
+			g.Sub(2)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(0), g.Get())
+	assert.NotPanics(t, func() { NoopGauge.Track()() })
+}
+
 func BenchmarkGaugeInc(b *testing.B) {
 	c := RootScope(NewStaticCollector()).Gauge("foo")
 
@@ -1,9 +1,12 @@
 package stats
 
 import (
-	"fmt"
 	"math"
+	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var defaultCutoffs = []float64{
@@ -29,6 +32,15 @@ type HistogramValue struct {
 	Count   int64
 	Sum     float64
 	Buckets []Bucket
+
+	// Native holds the exponential sparse buckets of the histograms
+	// configured with NativeBuckets, it is nil otherwise.
+	Native *NativeHistogramValue `json:",omitempty"`
+
+	// Extrema holds the extrema of the observations of the histograms
+	// configured with TrackExtrema, it is nil otherwise or if nothing was
+	// observed within the extrema windows.
+	Extrema *HistogramExtrema `json:",omitempty"`
 }
 
 // HistogramVectorGetter provides read access to histogram vectors for collectors.
@@ -49,81 +61,103 @@ type HistogramVector interface {
 	// WithLabels returns a Histogram with the specified label values.
 	// The number of values must match the number of labels defined for this vector.
 	WithLabels(...string) Histogram
+
+	// Bind returns the Histogram of the specified label values like WithLabels,
+	// and pins its series so it is never evicted by WithTTL. The Histogram is
+	// meant to be kept and reused instead of looking the series up again.
+	Bind(...string) Histogram
+
+	// Delete removes the histogram with the specified label values, it returns
+	// false if no such histogram exists. The histograms previously returned by
+	// WithLabels for these values are detached from the vector.
+	Delete(...string) bool
+
+	// Reset removes all the histograms of the vector, including the ones
+	// created from other scopes registering the same name.
+	Reset()
 }
 
 type histogramVector struct {
-	labels  []string
-	cutoffs []float64
+	entityVector
 
-	mu sync.RWMutex
-	hs map[uint64]*histogram
+	cutoffs      []float64
+	native       *nativeOptions
+	extrema      *extremaOptions
+	observations observationOptions
 
-	marshaler labelMarshaler
+	// onInvalid is called with the label values of the histograms counting
+	// their invalid observations.
+	onInvalid func([]string, int64)
 }
 
-func (hv *histogramVector) Labels() []string   { return hv.labels }
-func (hv *histogramVector) Cutoffs() []float64 { return hv.cutoffs }
-
-func (hv *histogramVector) buildTags(key uint64) map[string]string {
-	var tags = make(map[string]string, len(hv.labels))
+func newHistogramVector(ls []string, lm labelMarshaler, opts ...HistogramOption) *histogramVector {
+	hv := &histogramVector{
+		entityVector: entityVector{labels: ls, marshaler: lm, now: time.Now},
+		cutoffs:      defaultCutoffs,
+		observations: defaultObservationOptions,
+	}
 
-	for i, val := range hv.marshaler.unmarshal(key, len(hv.labels)) {
-		tags[hv.labels[i]] = val
+	for _, opt := range opts {
+		opt.applyHistogram(hv)
 	}
 
-	return tags
+	hv.newFunc = hv.newHistogram
+
+	return hv
 }
 
-func (hv *histogramVector) Get() []*HistogramValue {
-	var res = make([]*HistogramValue, 0, len(hv.hs))
+func (hv *histogramVector) newHistogram(tags map[string]string) interface{} {
+	h := &histogram{cutoffs: hv.cutoffs, observations: &hv.observations}
 
-	for k, h := range hv.hs {
-		res = append(
-			res,
-			&HistogramValue{
-				Tags:    hv.buildTags(k),
-				Count:   h.Count(),
-				Sum:     h.Sum(),
-				Buckets: h.Buckets(),
-			},
-		)
-	}
+	for i := range h.counts {
+		h.counts[i] = &histogramCounts{buckets: make([]atomicInt64, len(hv.cutoffs))}
 
-	return res
-}
+		if hv.native != nil {
+			h.counts[i].native = newNativeHistogram(hv.native)
+		}
+	}
 
-func (hv *histogramVector) WithLabels(ls ...string) Histogram {
-	if len(ls) != len(hv.labels) {
-		panic(
-			fmt.Sprintf(
-				"Not the correct number of labels: labels: %v, values: %v",
-				hv.labels,
-				ls,
-			),
-		)
+	if hv.extrema != nil {
+		h.extrema = newHistogramExtrema(hv.extrema, hv.timestamp)
 	}
 
-	k := hv.marshaler.marshal(ls)
+	if hv.onInvalid != nil {
+		var vs = make([]string, len(hv.labels))
 
-	hv.mu.RLock()
-	h, ok := hv.hs[k]
-	hv.mu.RUnlock()
+		for i, l := range hv.labels {
+			vs[i] = tags[l]
+		}
 
-	if ok {
-		return h
+		h.onInvalid = func(n int64) { hv.onInvalid(vs, n) }
 	}
 
-	hv.mu.Lock()
+	return h
+}
 
-	h = &histogram{
-		cutoffs: hv.cutoffs,
-		counts:  make([]atomicInt64, len(hv.cutoffs)),
-	}
+func (hv *histogramVector) Labels() []string    { return hv.labels }
+func (hv *histogramVector) Cutoffs() []float64  { return hv.cutoffs }
+func (hv *histogramVector) Metadata() Metadata  { return hv.metadata }
+func (hv *histogramVector) TracksExtrema() bool { return hv.extrema != nil }
 
-	hv.hs[k] = h
+func (hv *histogramVector) Get() []*HistogramValue {
+	var res []*HistogramValue
 
-	hv.mu.Unlock()
-	return h
+	hv.rangeEntities(func(tags map[string]string, e interface{}) {
+		v := e.(*histogram).value()
+		v.Tags = tags
+
+		res = append(res, v)
+	})
+
+	return res
+}
+
+func (hv *histogramVector) WithLabels(ls ...string) Histogram {
+	return hv.entity(ls).(*histogram)
+}
+
+func (hv *histogramVector) Bind(ls ...string) Histogram {
+	return hv.bind(ls).(*histogram)
 }
 
 // Bucket represents a single histogram bucket with its count and upper bound.
@@ -138,6 +172,13 @@ type Histogram interface {
 	// Record adds a single observation to the histogram.
 	Record(float64)
 
+	// RecordN adds n observations of the same value to the histogram.
+	RecordN(float64, int64)
+
+	// RecordMany adds all the given observations to the histogram, it is
+	// cheaper than calling Record for each of them.
+	RecordMany([]float64)
+
 	// Count returns the total number of observations.
 	Count() int64
 
@@ -148,52 +189,234 @@ type Histogram interface {
 	Buckets() []Bucket
 }
 
+// histogramCounts holds the observations of a histogram, count is the number
+// of observations fully recorded into the buckets, the sum and the native
+// buckets if any.
+type histogramCounts struct {
+	count   uint64
+	sum     atomicFloat64
+	buckets []atomicInt64
+	native  *nativeHistogram
+}
+
+// histogram records into the hot counts while the cold ones are kept still
+// for the reads. The highest bit of countAndHotIdx is the index of the hot
+// counts, the other bits count the started observations. A read flips the hot
+// index, waits for the observations started on the now cold counts to be
+// fully recorded, then merges the cold counts into the hot ones.
 type histogram struct {
-	cutoffs []float64
+	cutoffs      []float64
+	observations *observationOptions
+	onInvalid    func(int64)
 
-	sum    atomicFloat64
-	counts []atomicInt64
+	countAndHotIdx uint64
+
+	mu     sync.Mutex
+	counts [2]*histogramCounts
+
+	extrema *histogramExtrema
 }
 
-func (h *histogram) Record(v float64) {
+// linearSearchMaxCutoffs is the number of cutoffs up to which the bucket of
+// an observation is looked up linearly, a binary search is faster above.
+const linearSearchMaxCutoffs = 16
+
+// bucketIndex returns the index of the bucket of v, -1 is returned if v is
+// greater than every cutoff.
+func (h *histogram) bucketIndex(v float64) int {
+	if len(h.cutoffs) > linearSearchMaxCutoffs {
+		if i := sort.SearchFloat64s(h.cutoffs, v); i < len(h.cutoffs) {
+			return i
+		}
+
+		return -1
+	}
+
 	for i, c := range h.cutoffs {
 		if v <= c {
-			h.counts[i].Inc()
-			h.sum.Add(v)
-			break
+			return i
 		}
 	}
+
+	return -1
 }
 
-func (h *histogram) Sum() float64 { return h.sum.Get() }
+// hotCounts starts n observations and returns the counts to record them
+// into, the observations are complete once added to the count.
+func (h *histogram) hotCounts(n int64) *histogramCounts {
+	return h.counts[atomic.AddUint64(&h.countAndHotIdx, uint64(n))>>63]
+}
 
-func (h *histogram) Count() int64 {
-	var res int64
+func (h *histogram) Record(v float64) { h.RecordN(v, 1) }
 
-	for _, c := range h.counts {
-		res += c.Get()
+func (h *histogram) RecordN(v float64, n int64) {
+	if n <= 0 {
+		return
 	}
 
-	return res
+	v, ok := h.observations.sanitize(v, n, h.onInvalid)
+
+	if !ok {
+		return
+	}
+
+	if i := h.bucketIndex(v); i >= 0 {
+		hc := h.hotCounts(n)
+
+		hc.buckets[i].Add(n)
+		hc.sum.Add(v * float64(n))
+
+		if hc.native != nil {
+			hc.native.record(v, n)
+		}
+
+		atomic.AddUint64(&hc.count, uint64(n))
+
+		if h.extrema != nil {
+			h.extrema.record(v, v)
+		}
+	}
 }
 
-func (h *histogram) Buckets() []Bucket {
-	var bs = make([]Bucket, len(h.cutoffs))
+func (h *histogram) RecordMany(vs []float64) {
+	var (
+		counts = make([]int64, len(h.cutoffs))
+		n      int64
+		sum    float64
+
+		natives []float64
+
+		minV = math.Inf(1)
+		maxV = math.Inf(-1)
+	)
+
+	for _, v := range vs {
+		v, ok := h.observations.sanitize(v, 1, h.onInvalid)
+
+		if !ok {
+			continue
+		}
+
+		i := h.bucketIndex(v)
+
+		if i < 0 {
+			continue
+		}
+
+		if h.counts[0].native != nil {
+			natives = append(natives, v)
+		}
+
+		counts[i]++
+		n++
+		sum += v
+
+		minV = math.Min(minV, v)
+		maxV = math.Max(maxV, v)
+	}
+
+	if n == 0 {
+		return
+	}
+
+	hc := h.hotCounts(n)
+
+	for i, c := range counts {
+		if c > 0 {
+			hc.buckets[i].Add(c)
+		}
+	}
+
+	hc.sum.Add(sum)
+
+	if hc.native != nil {
+		hc.native.recordMany(natives)
+	}
+
+	atomic.AddUint64(&hc.count, uint64(n))
+
+	if h.extrema != nil {
+		h.extrema.record(minV, maxV)
+	}
+}
+
+func (h *histogram) Sum() float64      { return h.snapshot().Sum }
+func (h *histogram) Count() int64      { return h.snapshot().Count }
+func (h *histogram) Buckets() []Bucket { return h.snapshot().Buckets }
+
+// value returns the value of the histogram for the collectors.
+func (h *histogram) value() *HistogramValue {
+	v := h.snapshot()
+
+	if h.extrema != nil {
+		v.Extrema = h.extrema.value()
+	}
+
+	return &v
+}
+
+// snapshot returns the classic and native buckets of the histogram, the
+// count, the sum and the buckets agree with each other.
+func (h *histogram) snapshot() HistogramValue {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var (
+		n     = atomic.AddUint64(&h.countAndHotIdx, 1<<63)
+		count = n & (1<<63 - 1)
+
+		hot  = h.counts[n>>63]
+		cold = h.counts[(^n)>>63]
+	)
+
+	for atomic.LoadUint64(&cold.count) != count {
+		runtime.Gosched()
+	}
+
+	v := HistogramValue{
+		Count:   int64(count),
+		Sum:     cold.sum.Get(),
+		Buckets: make([]Bucket, len(h.cutoffs)),
+	}
 
 	for i, cutoff := range h.cutoffs {
-		bs[i].UpperBound = cutoff
-		bs[i].Count = h.counts[i].Get()
+		c := cold.buckets[i].Get()
+
+		v.Buckets[i] = Bucket{UpperBound: cutoff, Count: c}
+
+		hot.buckets[i].Add(c)
+		cold.buckets[i].Update(0)
 	}
 
-	return bs
+	hot.sum.Add(v.Sum)
+	cold.sum.Update(0)
+
+	if cold.native != nil {
+		v.Native = cold.native.value()
+		cold.native.mergeInto(hot.native)
+	}
+
+	atomic.AddUint64(&hot.count, count)
+	atomic.StoreUint64(&cold.count, 0)
+
+	return v
 }
 
 // StaticBuckets creates a HistogramOption that configures custom bucket boundaries.
 // An infinity bucket is automatically appended to catch all values above the highest cutoff.
+//
+// StaticBuckets panics if the cutoffs are not finite, sorted and deduplicated.
 func StaticBuckets(cutoffs []float64) HistogramOption {
-	return func(hv *histogramVector) {
-		hv.cutoffs = append(cutoffs, math.Inf(0))
-	}
+	cutoffs = validateCutoffs(cutoffs)
+
+	var cs = make([]float64, len(cutoffs), len(cutoffs)+1)
+
+	copy(cs, cutoffs)
+	cs = append(cs, math.Inf(0))
+
+	return histogramOptionFunc(func(hv *histogramVector) {
+		hv.cutoffs = cs
+	})
 }
 
 type partialHistogramVector struct {
@@ -202,9 +425,19 @@ type partialHistogramVector struct {
 }
 
 func (phv partialHistogramVector) WithLabels(labels ...string) Histogram {
-	return phv.hv.WithLabels(append(phv.vs, labels...)...)
+	return withLabelValues(phv.vs, labels, phv.hv.WithLabels)
+}
+
+func (phv partialHistogramVector) Bind(ls ...string) Histogram {
+	return withLabelValues(phv.vs, ls, phv.hv.Bind)
+}
+
+func (phv partialHistogramVector) Delete(ls ...string) bool {
+	return withLabelValues(phv.vs, ls, phv.hv.Delete)
 }
 
+func (phv partialHistogramVector) Reset() { phv.hv.Reset() }
+
 type reorderHistogramVector struct {
 	hv HistogramVector
 	labelOrderer
@@ -214,6 +447,16 @@ func (rhv reorderHistogramVector) WithLabels(ls ...string) Histogram {
 	return rhv.hv.WithLabels(rhv.order(ls)...)
 }
 
+func (rhv reorderHistogramVector) Bind(ls ...string) Histogram {
+	return rhv.hv.Bind(rhv.order(ls)...)
+}
+
+func (rhv reorderHistogramVector) Delete(ls ...string) bool {
+	return rhv.hv.Delete(rhv.order(ls)...)
+}
+
+func (rhv reorderHistogramVector) Reset() { rhv.hv.Reset() }
+
 var (
 	// NoopHistogram is a histogram that discards all operations.
 	NoopHistogram Histogram = noopHistogram{}
@@ -224,13 +467,19 @@ var (
 
 type noopHistogram struct{}
 
-func (noopHistogram) Record(float64)    {}
-func (noopHistogram) Count() int64      { return 0 }
-func (noopHistogram) Sum() float64      { return 0 }
-func (noopHistogram) Buckets() []Bucket { return nil }
+func (noopHistogram) Record(float64)         {}
+func (noopHistogram) RecordN(float64, int64) {}
+func (noopHistogram) RecordMany([]float64)   {}
+func (noopHistogram) Count() int64           { return 0 }
+func (noopHistogram) Sum() float64           { return 0 }
+func (noopHistogram) Buckets() []Bucket      { return nil }
 
 type noopHistogramVector struct{}
 
 func (noopHistogramVector) WithLabels(...string) Histogram {
 	return noopHistogram{}
 }
+
+func (noopHistogramVector) Bind(...string) Histogram { return noopHistogram{} }
+func (noopHistogramVector) Delete(...string) bool    { return false }
+func (noopHistogramVector) Reset()                   {}
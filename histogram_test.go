@@ -2,7 +2,12 @@ package stats
 
 import (
 	"math"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func buildBuckets(cutoffs []float64, vs ...float64) []Bucket {
@@ -26,6 +31,16 @@ func buildBuckets(cutoffs []float64, vs ...float64) []Bucket {
 	return bs
 }
 
+func upperBounds(bs []Bucket) []float64 {
+	var res = make([]float64, len(bs))
+
+	for i, b := range bs {
+		res[i] = b.UpperBound
+	}
+
+	return res
+}
+
 func TestHistogram(t *testing.T) {
 	for _, tt := range []struct {
 		name       string
@@ -164,6 +179,148 @@ func TestHistogram(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "reject invalid observations",
+			mutate: func(s Scope) {
+				h := s.Histogram("foo", StaticBuckets(nil), ObservationRange(0, 10))
+
+				h.Record(math.NaN())
+				h.Record(-1)
+				h.Record(11)
+				h.Record(1)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Histograms: []HistogramSnapshot{
+						{
+							Name: "foo",
+							Value: HistogramValue{
+								Tags:    map[string]string{},
+								Count:   1,
+								Sum:     1,
+								Buckets: buildBuckets([]float64{math.Inf(0)}, 1),
+							},
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "clamp invalid observations",
+			mutate: func(s Scope) {
+				h := s.Histogram(
+					"foo",
+					StaticBuckets(nil),
+					ObservationRange(0, 10),
+					OnInvalidObservation(ClampInvalidObservations),
+				)
+
+				h.Record(math.NaN())
+				h.Record(-1)
+				h.Record(11)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Histograms: []HistogramSnapshot{
+						{
+							Name: "foo",
+							Value: HistogramValue{
+								Tags:    map[string]string{},
+								Count:   2,
+								Sum:     10,
+								Buckets: buildBuckets([]float64{math.Inf(0)}, 0, 10),
+							},
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "count invalid observations",
+			mutate: func(s Scope) {
+				hv := s.Scope("bar", map[string]string{"fiz": "buz"}).HistogramVector(
+					"foo",
+					[]string{"biz"},
+					StaticBuckets(nil),
+					ObservationRange(0, math.Inf(0)),
+					OnInvalidObservation(CountInvalidObservations),
+				)
+
+				hv.WithLabels("a").Record(math.NaN())
+				hv.WithLabels("a").Record(-1)
+				hv.WithLabels("b").Record(-1)
+			},
+			introspect: func(t *testing.T, sn Snapshot) {
+				assert.Equal(
+					t,
+					[]Int64Snapshot{
+						{
+							Name:     "bar_foo_invalid_observations",
+							Labels:   map[string]string{"biz": "a", "fiz": "buz"},
+							Value:    2,
+							Metadata: Metadata{Help: "Number of invalid observations dropped by bar_foo"},
+						},
+						{
+							Name:     "bar_foo_invalid_observations",
+							Labels:   map[string]string{"biz": "b", "fiz": "buz"},
+							Value:    1,
+							Metadata: Metadata{Help: "Number of invalid observations dropped by bar_foo"},
+						},
+					},
+					sn.Counters,
+				)
+
+				for _, h := range sn.Histograms {
+					assert.Equal(t, int64(0), h.Value.Count)
+				}
+			},
+		},
+		{
+			name: "batch recording",
+			mutate: func(s Scope) {
+				h := s.Histogram(
+					"foo",
+					StaticBuckets([]float64{1, 2}),
+					ObservationRange(0, 10),
+				)
+
+				h.RecordN(1.5, 3)
+				h.RecordN(1.5, 0)
+				h.RecordMany([]float64{.5, 2.5, -1, math.NaN(), 1})
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Histograms: []HistogramSnapshot{
+						{
+							Name: "foo",
+							Value: HistogramValue{
+								Tags:    map[string]string{},
+								Count:   6,
+								Sum:     8.5,
+								Buckets: buildBuckets([]float64{1, 2, math.Inf(0)}, 1.5, 1.5, 1.5, .5, 2.5, 1),
+							},
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "binary search of the buckets",
+			mutate: func(s Scope) {
+				h := s.Histogram("foo", LinearBuckets(1, 1, 32))
+
+				h.RecordMany([]float64{0, 1, 1.5, 31, 32, 33})
+			},
+			introspect: func(t *testing.T, sn Snapshot) {
+				bs := sn.Histograms[0].Value.Buckets
+
+				assert.Equal(
+					t,
+					buildBuckets(upperBounds(bs), 0, 1, 1.5, 31, 32, 33),
+					bs,
+				)
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewStaticCollector()
@@ -174,6 +331,199 @@ func TestHistogram(t *testing.T) {
 	}
 }
 
+func TestHistogramInvalidObservationsConflict(t *testing.T) {
+	var (
+		errs []error
+
+		s = RootScope(
+			NewStaticCollector(),
+			WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		)
+	)
+
+	s.Gauge("foo" + InvalidObservationsSuffix)
+
+	h := s.Histogram(
+		"foo",
+		StaticBuckets(nil),
+		ObservationRange(0, 1),
+		OnInvalidObservation(CountInvalidObservations),
+	)
+
+	assert.Equal(
+		t,
+		[]error{
+			&ConflictError{
+				Name: "foo_invalid_observations",
+				Kind: TypeConflict,
+				Has:  "histogram",
+				Want: "gauge",
+			},
+		},
+		errs,
+	)
+
+	h.Record(2)
+
+	assert.Len(t, errs, 1)
+}
+
+func TestHistogramCutoffsConsistency(t *testing.T) {
+	s := RootScope(NewStaticCollector())
+
+	s.Histogram("foo", StaticBuckets([]float64{1, 2}))
+
+	assert.NotPanics(t, func() { s.Histogram("foo", StaticBuckets([]float64{1, 2})) })
+	assert.NotPanics(t, func() { s.Histogram("foo", LinearBuckets(1, 1, 2)) })
+	assert.Panics(t, func() { s.Histogram("foo", StaticBuckets([]float64{1, 3})) })
+	assert.Panics(t, func() { s.Histogram("foo") })
+
+	s.HistogramVector("bar", []string{"a", "b"})
+
+	assert.NotPanics(t, func() { s.HistogramVector("bar", []string{"b", "a"}) })
+	assert.Panics(
+		t,
+		func() { s.HistogramVector("bar", []string{"a", "b"}, StaticBuckets(nil)) },
+	)
+}
+
+func TestConcurrentHistogramVector(t *testing.T) {
+	var (
+		c  = NewStaticCollector()
+		hv = RootScope(c).HistogramVector(
+			"foo",
+			[]string{"bar"},
+			StaticBuckets([]float64{1, 2}),
+			NativeBuckets(0),
+		)
+
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				hv.WithLabels(strconv.Itoa(i*100 + j%10)).Record(float64(j % 3))
+			}
+		}(i)
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		for _, h := range c.Get().Histograms {
+			var count int64
+
+			for _, b := range h.Value.Buckets {
+				count += b.Count
+			}
+
+			assert.Equal(t, count, h.Value.Count)
+		}
+
+		select {
+		case <-done:
+			assert.Len(t, c.Get().Histograms, 80)
+			return
+		default:
+		}
+	}
+}
+
+func TestHistogramConsistentValue(t *testing.T) {
+	var (
+		h = RootScope(NewStaticCollector()).Histogram(
+			"foo",
+			StaticBuckets([]float64{1}),
+		).(*histogram)
+
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				h.Record(2)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		v := h.value()
+
+		assert.Equal(t, float64(2*v.Count), v.Sum)
+		assert.Equal(t, v.Count, v.Buckets[1].Count)
+
+		select {
+		case <-done:
+			assert.Equal(t, int64(4000), h.Count())
+			assert.Equal(t, 8000., h.Sum())
+			return
+		default:
+		}
+	}
+}
+
+func TestHistogramExtrema(t *testing.T) {
+	var (
+		now = time.Now()
+
+		s  = RootScope(NewStaticCollector())
+		h  = s.Histogram("foo", StaticBuckets(nil), TrackExtrema(time.Minute))
+		hv = s.rootScope().histograms["foo"]
+	)
+
+	hv.now = func() time.Time { return now }
+
+	assert.Nil(t, hv.Get()[0].Extrema)
+
+	h.Record(3)
+	h.Record(-1)
+	h.Record(2)
+
+	now = now.Add(30 * time.Second)
+
+	assert.Equal(t, &HistogramExtrema{Min: -1, Max: 3}, hv.Get()[0].Extrema)
+	assert.Equal(t, &HistogramExtrema{Min: -1, Max: 3}, hv.Get()[0].Extrema)
+
+	now = now.Add(45 * time.Second)
+	h.Record(1)
+
+	assert.Equal(t, &HistogramExtrema{Min: -1, Max: 3}, hv.Get()[0].Extrema)
+
+	now = now.Add(55 * time.Second)
+
+	assert.Equal(t, &HistogramExtrema{Min: 1, Max: 1}, hv.Get()[0].Extrema)
+
+	now = now.Add(70 * time.Second)
+	h.Record(5)
+
+	assert.Equal(t, &HistogramExtrema{Min: 5, Max: 5}, hv.Get()[0].Extrema)
+
+	now = now.Add(2 * time.Minute)
+
+	assert.Nil(t, hv.Get()[0].Extrema)
+	assert.Equal(t, int64(5), h.Count())
+}
+
 func BenchmarkHistogramInc(b *testing.B) {
 	c := RootScope(NewStaticCollector()).Histogram("foo")
 
@@ -184,6 +534,42 @@ func BenchmarkHistogramInc(b *testing.B) {
 	}
 }
 
+func BenchmarkHistogramRecordMany(b *testing.B) {
+	var (
+		h  = RootScope(NewStaticCollector()).Histogram("foo", ExponentialBuckets(.001, 1.5, 32))
+		vs = make([]float64, 1000)
+	)
+
+	for i := range vs {
+		vs[i] = float64(i) / 100
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.RecordMany(vs)
+	}
+}
+
+func BenchmarkHistogramRecordLoop(b *testing.B) {
+	var (
+		h  = RootScope(NewStaticCollector()).Histogram("foo", ExponentialBuckets(.001, 1.5, 32))
+		vs = make([]float64, 1000)
+	)
+
+	for i := range vs {
+		vs[i] = float64(i) / 100
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range vs {
+			h.Record(v)
+		}
+	}
+}
+
 func BenchmarkVectorHistogram(b *testing.B) {
 	c := RootScope(NewStaticCollector()).HistogramVector(
 		"foo",
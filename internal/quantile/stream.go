@@ -0,0 +1,193 @@
+// Package quantile implements the targeted quantiles streaming algorithm
+// described in "Effective Computation of Biased Quantiles over Data Streams"
+// by Cormode, Korn, Muthukrishnan and Srivastava.
+package quantile
+
+import (
+	"math"
+	"sort"
+)
+
+const defaultBufferSize = 500
+
+// Target is a quantile to track along with its allowed error.
+type Target struct {
+	Quantile float64
+	Epsilon  float64
+}
+
+type sample struct {
+	value float64
+	width float64
+	delta float64
+}
+
+// Stream computes approximated quantiles over a stream of values.
+// A Stream is not safe for concurrent use.
+type Stream struct {
+	targets []Target
+
+	n       float64
+	samples []sample
+	buf     []float64
+}
+
+// NewStream returns a stream tracking the given targets.
+func NewStream(targets []Target) *Stream {
+	return &Stream{
+		targets: targets,
+		buf:     make([]float64, 0, defaultBufferSize),
+	}
+}
+
+// Insert adds a value to the stream.
+func (s *Stream) Insert(v float64) {
+	s.buf = append(s.buf, v)
+
+	if len(s.buf) == cap(s.buf) {
+		s.flush()
+	}
+}
+
+// Count returns the number of values inserted since the last reset.
+func (s *Stream) Count() int64 {
+	return int64(s.n) + int64(len(s.buf))
+}
+
+// Reset drops all the values inserted so far.
+func (s *Stream) Reset() {
+	s.n = 0
+	s.samples = s.samples[:0]
+	s.buf = s.buf[:0]
+}
+
+// Query returns the approximated value of the quantile q, NaN is returned
+// if the stream is empty.
+func (s *Stream) Query(q float64) float64 {
+	s.flush()
+
+	if len(s.samples) == 0 {
+		return math.NaN()
+	}
+
+	var (
+		t = math.Ceil(q * s.n)
+		p = s.samples[0]
+		r float64
+	)
+
+	t += math.Ceil(s.invariant(t) / 2)
+
+	for _, c := range s.samples[1:] {
+		r += p.width
+
+		if r+c.width+c.delta > t {
+			return p.value
+		}
+
+		p = c
+	}
+
+	return p.value
+}
+
+func (s *Stream) invariant(r float64) float64 {
+	var m = math.MaxFloat64
+
+	for _, t := range s.targets {
+		var f float64
+
+		if t.Quantile*s.n <= r {
+			f = (2 * t.Epsilon * r) / t.Quantile
+		} else {
+			f = (2 * t.Epsilon * (s.n - r)) / (1 - t.Quantile)
+		}
+
+		if f < m {
+			m = f
+		}
+	}
+
+	return m
+}
+
+func (s *Stream) flush() {
+	if len(s.buf) == 0 {
+		return
+	}
+
+	sort.Float64s(s.buf)
+	s.merge(s.buf)
+	s.buf = s.buf[:0]
+}
+
+func (s *Stream) merge(vs []float64) {
+	var (
+		r float64
+		i int
+	)
+
+	for _, v := range vs {
+		inserted := false
+
+		for ; i < len(s.samples); i++ {
+			c := s.samples[i]
+
+			if c.value > v {
+				s.samples = append(s.samples, sample{})
+				copy(s.samples[i+1:], s.samples[i:])
+				s.samples[i] = sample{
+					value: v,
+					width: 1,
+					delta: math.Max(0, math.Floor(s.invariant(r))-1),
+				}
+				i++
+				inserted = true
+
+				break
+			}
+
+			r += c.width
+		}
+
+		if !inserted {
+			s.samples = append(s.samples, sample{value: v, width: 1})
+			i++
+		}
+
+		s.n++
+		r++
+	}
+
+	s.compress()
+}
+
+func (s *Stream) compress() {
+	if len(s.samples) < 2 {
+		return
+	}
+
+	var (
+		xi = len(s.samples) - 1
+		x  = s.samples[xi]
+		r  = s.n - 1 - x.width
+	)
+
+	for i := len(s.samples) - 2; i >= 0; i-- {
+		c := s.samples[i]
+
+		if c.width+x.width+x.delta <= s.invariant(r) {
+			x.width += c.width
+			s.samples[xi] = x
+
+			copy(s.samples[i:], s.samples[i+1:])
+			s.samples = s.samples[:len(s.samples)-1]
+			xi--
+		} else {
+			x = c
+			xi = i
+		}
+
+		r -= c.width
+	}
+}
@@ -0,0 +1,52 @@
+package quantile
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	var (
+		targets = []Target{
+			{Quantile: .5, Epsilon: .05},
+			{Quantile: .9, Epsilon: .01},
+			{Quantile: .99, Epsilon: .001},
+		}
+
+		s  = NewStream(targets)
+		r  = rand.New(rand.NewSource(42))
+		vs = make([]float64, 10000)
+	)
+
+	assert.True(t, math.IsNaN(s.Query(.5)))
+
+	for i := range vs {
+		vs[i] = r.NormFloat64()
+		s.Insert(vs[i])
+	}
+
+	sort.Float64s(vs)
+
+	assert.Equal(t, int64(len(vs)), s.Count())
+
+	for _, tt := range targets {
+		var (
+			v    = s.Query(tt.Quantile)
+			rank = sort.SearchFloat64s(vs, v)
+			got  = float64(rank) / float64(len(vs))
+		)
+
+		assert.InDelta(t, tt.Quantile, got, tt.Epsilon, "quantile %v", tt.Quantile)
+	}
+
+	assert.Less(t, len(s.samples), len(vs)/2)
+
+	s.Reset()
+
+	assert.Equal(t, int64(0), s.Count())
+	assert.True(t, math.IsNaN(s.Query(.5)))
+}
@@ -6,14 +6,39 @@ import (
 	"github.com/upfluence/stats/internal/hash"
 )
 
+// labelMarshaler maps the label values to the keys of the series. The keys
+// are allocated when the values are first retained and are never reused.
 type labelMarshaler interface {
-	marshal([]string) uint64
-	unmarshal(uint64, int) []string
+	// marshal returns the key of the values, false is returned if the values
+	// are not retained.
+	marshal([]string) (uint64, bool)
+	unmarshal(uint64) ([]string, bool)
+
+	// retain returns the key of the values, allocating it if needed. Each call
+	// must be balanced by a call to release once the series is deleted.
+	retain([]string) uint64
+	release(uint64)
 }
 
 func newDefaultMarshaler() labelMarshaler {
+	return newHashingMarshaler(hashValues)
+}
+
+func hashValues(vs []string) uint64 {
+	res := hash.New()
+
+	for _, v := range vs {
+		res = hash.Add(res, v)
+	}
+
+	return res
+}
+
+func newHashingMarshaler(fn func([]string) uint64) *hashingMarshaler {
 	return &hashingMarshaler{
-		st: make(map[hashingKey][]string),
+		hash:    fn,
+		buckets: make(map[hashingKey][]*hashingEntry),
+		keys:    make(map[uint64]*hashingEntry),
 	}
 }
 
@@ -22,28 +47,128 @@ type hashingKey struct {
 	len  int
 }
 
+// hashingEntry counts the series referencing the values, the marshaler is
+// shared by all the vectors of a root scope.
+type hashingEntry struct {
+	key  uint64
+	vs   []string
+	refs int
+}
+
+// hashingMarshaler chains the values whose hashes collide, the values are
+// compared on lookup so colliding values never share a series.
 type hashingMarshaler struct {
+	hash func([]string) uint64
+
 	sync.RWMutex
-	st map[hashingKey][]string
+	buckets map[hashingKey][]*hashingEntry
+	keys    map[uint64]*hashingEntry
+	lastKey uint64
 }
 
-func (hm *hashingMarshaler) marshal(vs []string) uint64 {
-	res := hash.New()
+func equalStrings(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
 
-	for _, v := range vs {
-		res = hash.Add(res, v)
+	for i, v := range x {
+		if v != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (hm *hashingMarshaler) lookup(k hashingKey, vs []string) *hashingEntry {
+	for _, e := range hm.buckets[k] {
+		if equalStrings(e.vs, vs) {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (hm *hashingMarshaler) marshal(vs []string) (uint64, bool) {
+	var k = hashingKey{hash: hm.hash(vs), len: len(vs)}
+
+	hm.RLock()
+	e := hm.lookup(k, vs)
+	hm.RUnlock()
+
+	if e == nil {
+		return 0, false
 	}
 
+	return e.key, true
+}
+
+func (hm *hashingMarshaler) retain(vs []string) uint64 {
+	var k = hashingKey{hash: hm.hash(vs), len: len(vs)}
+
 	hm.Lock()
-	hm.st[hashingKey{hash: res, len: len(vs)}] = vs
-	hm.Unlock()
+	defer hm.Unlock()
 
-	return res
+	if e := hm.lookup(k, vs); e != nil {
+		e.refs++
+		return e.key
+	}
+
+	hm.lastKey++
+
+	e := &hashingEntry{key: hm.lastKey, vs: append(vs[:0:0], vs...), refs: 1}
+
+	hm.buckets[k] = append(hm.buckets[k], e)
+	hm.keys[e.key] = e
+
+	return e.key
+}
+
+func (hm *hashingMarshaler) release(key uint64) {
+	hm.Lock()
+	defer hm.Unlock()
+
+	e, ok := hm.keys[key]
+
+	if !ok {
+		return
+	}
+
+	if e.refs--; e.refs > 0 {
+		return
+	}
+
+	delete(hm.keys, key)
+
+	var (
+		k  = hashingKey{hash: hm.hash(e.vs), len: len(e.vs)}
+		es = hm.buckets[k]
+	)
+
+	for i, ce := range es {
+		if ce == e {
+			es = append(es[:i:i], es[i+1:]...)
+			break
+		}
+	}
+
+	if len(es) == 0 {
+		delete(hm.buckets, k)
+	} else {
+		hm.buckets[k] = es
+	}
 }
 
-func (hm *hashingMarshaler) unmarshal(h uint64, len int) []string {
+func (hm *hashingMarshaler) unmarshal(key uint64) ([]string, bool) {
 	hm.RLock()
 	defer hm.RUnlock()
 
-	return hm.st[hashingKey{hash: h, len: len}]
+	e, ok := hm.keys[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	return e.vs, true
 }
@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collidingHash([]string) uint64 { return 42 }
+
+func TestHashingMarshalerCollision(t *testing.T) {
+	hm := newHashingMarshaler(collidingHash)
+
+	ka := hm.retain([]string{"a"})
+	kb := hm.retain([]string{"b"})
+
+	assert.NotEqual(t, ka, kb)
+	assert.Equal(t, ka, hm.retain([]string{"a"}))
+
+	for _, tt := range []struct {
+		vs  []string
+		key uint64
+		ok  bool
+	}{
+		{vs: []string{"a"}, key: ka, ok: true},
+		{vs: []string{"b"}, key: kb, ok: true},
+		{vs: []string{"c"}},
+		{vs: []string{"a", "b"}},
+	} {
+		k, ok := hm.marshal(tt.vs)
+
+		assert.Equal(t, tt.ok, ok, "%v", tt.vs)
+		assert.Equal(t, tt.key, k, "%v", tt.vs)
+
+		if !ok {
+			continue
+		}
+
+		vs, ok := hm.unmarshal(k)
+
+		assert.True(t, ok)
+		assert.Equal(t, tt.vs, vs)
+	}
+
+	hm.release(ka)
+	hm.release(kb)
+
+	_, ok := hm.marshal([]string{"b"})
+	assert.False(t, ok)
+
+	k, ok := hm.marshal([]string{"a"})
+	assert.True(t, ok)
+	assert.Equal(t, ka, k)
+
+	hm.release(ka)
+
+	assert.Empty(t, hm.keys)
+	assert.Empty(t, hm.buckets)
+
+	assert.NotEqual(t, ka, hm.retain([]string{"a"}))
+}
+
+func TestVectorHashCollision(t *testing.T) {
+	var (
+		hm = newHashingMarshaler(collidingHash)
+		cv = counterVector{newAtomicInt64Vector([]string{"bar"}, hm, metricOptions{})}
+	)
+
+	cv.WithLabels("a").Inc()
+	cv.WithLabels("b").Add(2)
+	cv.WithLabels("a").Inc()
+
+	assert.ElementsMatch(
+		t,
+		[]*Int64Value{
+			{Tags: map[string]string{"bar": "a"}, Value: 2},
+			{Tags: map[string]string{"bar": "b"}, Value: 2},
+		},
+		cv.Get(),
+	)
+
+	assert.True(t, cv.Delete("a"))
+	assert.Equal(t, int64(2), cv.WithLabels("b").Get())
+}
+
+func TestHashingMarshalerAllocations(t *testing.T) {
+	var (
+		hm = newDefaultMarshaler()
+		vs = []string{"foo", "bar"}
+	)
+
+	hm.retain(vs)
+
+	assert.Equal(
+		t,
+		0.,
+		testing.AllocsPerRun(100, func() { hm.marshal(vs) }),
+	)
+}
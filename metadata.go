@@ -0,0 +1,39 @@
+package stats
+
+// Metadata describes a metric, collectors may expose it along with the
+// metric values.
+type Metadata struct {
+	// Help is a human readable description of the metric.
+	Help string
+
+	// Unit is the unit the metric is expressed in, such as "seconds" or
+	// "bytes".
+	Unit string
+}
+
+// MetadataGetter is an optional interface implemented by the getters passed
+// to the collectors, it exposes the metadata of the metric.
+type MetadataGetter interface {
+	// Metadata returns the metadata of the metric.
+	Metadata() Metadata
+}
+
+// GetMetadata returns the metadata of the given getter, an empty Metadata is
+// returned if the getter does not implement MetadataGetter.
+func GetMetadata(g interface{}) Metadata {
+	if mg, ok := g.(MetadataGetter); ok {
+		return mg.Metadata()
+	}
+
+	return Metadata{}
+}
+
+// WithHelp configures the description of the metric.
+func WithHelp(h string) MetricOption {
+	return func(mo *metricOptions) { mo.metadata.Help = h }
+}
+
+// WithUnit configures the unit of the metric.
+func WithUnit(u string) MetricOption {
+	return func(mo *metricOptions) { mo.metadata.Unit = u }
+}
@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata(t *testing.T) {
+	var md = Metadata{Help: "foo help", Unit: "seconds"}
+
+	for _, tt := range []struct {
+		name       string
+		mutate     func(Scope)
+		introspect func(*testing.T, Snapshot)
+	}{
+		{
+			name: "counter",
+			mutate: func(s Scope) {
+				s.Counter("foo", WithHelp("foo help"), WithUnit("seconds")).Inc()
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{
+							Name:     "foo",
+							Labels:   map[string]string{},
+							Value:    1,
+							Metadata: md,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "first registration wins",
+			mutate: func(s Scope) {
+				s.Gauge("foo", WithHelp("foo help"), WithUnit("seconds")).Update(1)
+				s.Gauge("foo", WithHelp("other help")).Update(2)
+				s.Gauge("foo").Update(3)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{
+							Name:     "foo",
+							Labels:   map[string]string{},
+							Value:    3,
+							Metadata: md,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "float gauge in sub scope",
+			mutate: func(s Scope) {
+				s.Scope("bar", map[string]string{"fiz": "buz"}).FloatGauge(
+					"foo",
+					WithHelp("foo help"),
+					WithUnit("seconds"),
+				).Update(.5)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					FloatGauges: []Float64Snapshot{
+						{
+							Name:     "bar_foo",
+							Labels:   map[string]string{"fiz": "buz"},
+							Value:    .5,
+							Metadata: md,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "gauge func in incarnation scope",
+			mutate: func(s Scope) {
+				LocalIncarnationScope(s, "incarnation").GaugeFunc(
+					"foo",
+					func() int64 { return 4 },
+					WithHelp("foo help"),
+					WithUnit("seconds"),
+				)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{
+							Name:     "foo",
+							Labels:   map[string]string{"incarnation": "0"},
+							Value:    4,
+							Metadata: md,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "histogram",
+			mutate: func(s Scope) {
+				s.Histogram(
+					"foo",
+					StaticBuckets([]float64{1}),
+					WithHelp("foo help"),
+					WithUnit("seconds"),
+				).Record(.5)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Histograms: []HistogramSnapshot{
+						{
+							Name: "foo",
+							Value: HistogramValue{
+								Tags:  map[string]string{},
+								Count: 1,
+								Sum:   .5,
+								Buckets: []Bucket{
+									{Count: 1, UpperBound: 1},
+									{Count: 0, UpperBound: math.Inf(0)},
+								},
+							},
+							Metadata: md,
+						},
+					},
+				},
+			),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStaticCollector()
+
+			tt.mutate(RootScope(c))
+			tt.introspect(t, c.Get())
+		})
+	}
+}
+
+func TestMetadataSummaryFallback(t *testing.T) {
+	c := NewStaticCollector()
+	s := RootScope(int64OnlyCollector{Collector: c})
+
+	s.Summary(
+		"foo",
+		SummaryObjectives(map[float64]float64{}),
+		WithHelp("foo help"),
+	).Record(1)
+
+	// The sum float counter falls back on a rounded counter.
+
+	sn := c.Get()
+
+	assert.Equal(t, 2, len(sn.Counters))
+
+	for _, c := range sn.Counters {
+		assert.Equal(t, Metadata{Help: "foo help"}, c.Metadata)
+	}
+}
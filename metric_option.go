@@ -0,0 +1,55 @@
+package stats
+
+import "time"
+
+type metricOptions struct {
+	metadata  Metadata
+	ttl       time.Duration
+	maxSeries int64
+	striped   bool
+}
+
+// MetricOption configures a metric. It is accepted by every metric
+// constructor of Scope, including Histogram and Summary.
+type MetricOption func(*metricOptions)
+
+// WithTTL configures the series of the vector to be deleted once they have
+// not been accessed through WithLabels for the given duration. The idle
+// series are evicted when the vector is collected, a value held from an
+// evicted series is detached from the vector.
+func WithTTL(d time.Duration) MetricOption {
+	return func(mo *metricOptions) { mo.ttl = d }
+}
+
+// WithMaxSeries caps the number of series of the vector. Once the limit is
+// reached, WithLabels returns for any new label values the overflow series,
+// whose label values are all OverflowLabelValue, and increments the
+// CardinalityOverflowMetric counter of the root scope.
+func WithMaxSeries(n int) MetricOption {
+	return func(mo *metricOptions) { mo.maxSeries = int64(n) }
+}
+
+// Striped configures a counter to spread its increments across several
+// cells, one per processor, instead of a single atomic value. It removes the
+// contention of counters incremented from many goroutines at the cost of
+// memory and of slower reads: each cell fills a 64 bytes cache line and the
+// number of cells is GOMAXPROCS rounded up to a power of two, so a series
+// weighs 4KiB with GOMAXPROCS=64. Uncontended increments are slower than on
+// a plain counter, striping only pays off on hot counters updated from many
+// processors. It has no effect on the other metrics.
+func Striped() MetricOption {
+	return func(mo *metricOptions) { mo.striped = true }
+}
+
+func (o MetricOption) applyHistogram(hv *histogramVector) { o(&hv.metricOptions) }
+func (o MetricOption) applySummary(sv *summaryVector)     { o(&sv.metricOptions) }
+
+func buildMetricOptions(opts []MetricOption) metricOptions {
+	var mo metricOptions
+
+	for _, opt := range opts {
+		opt(&mo)
+	}
+
+	return mo
+}
@@ -26,12 +26,36 @@ func (cs multiCollector) RegisterGauge(n string, g stats.Int64VectorGetter) {
 	}
 }
 
+func (cs multiCollector) RegisterFloatCounter(n string, g stats.Float64VectorGetter) {
+	for _, c := range cs {
+		stats.RegisterFloatCounter(c, n, g)
+	}
+}
+
+func (cs multiCollector) RegisterFloatGauge(n string, g stats.Float64VectorGetter) {
+	for _, c := range cs {
+		stats.RegisterFloatGauge(c, n, g)
+	}
+}
+
 func (cs multiCollector) RegisterHistogram(n string, g stats.HistogramVectorGetter) {
 	for _, c := range cs {
 		c.RegisterHistogram(n, g)
 	}
 }
 
+func (cs multiCollector) RegisterSummary(n string, g stats.SummaryVectorGetter) {
+	for _, c := range cs {
+		stats.RegisterSummary(c, n, g)
+	}
+}
+
+func (cs multiCollector) Unregister(n string, g interface{}) {
+	for _, c := range cs {
+		stats.Unregister(c, n, g)
+	}
+}
+
 func WrapCollectors(cs ...stats.Collector) stats.Collector {
 	switch len(cs) {
 	case 0:
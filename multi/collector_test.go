@@ -76,3 +76,37 @@ func TestWrapCollectors(t *testing.T) {
 		assert.Equal(t, tt.out, WrapCollectors(tt.in...))
 	}
 }
+
+type mockFloatCollector struct {
+	mockCollector
+
+	registerFloatCounterCalled bool
+	registerFloatGaugeCalled   bool
+}
+
+func (m *mockFloatCollector) RegisterFloatCounter(string, stats.Float64VectorGetter) {
+	m.registerFloatCounterCalled = true
+}
+
+func (m *mockFloatCollector) RegisterFloatGauge(string, stats.Float64VectorGetter) {
+	m.registerFloatGaugeCalled = true
+}
+
+func TestRegisterFloat(t *testing.T) {
+	var (
+		fc mockFloatCollector
+		ic mockCollector
+
+		c = WrapCollectors(&fc, &ic).(stats.FloatCollector)
+	)
+
+	c.RegisterFloatCounter("foo", nil)
+	c.RegisterFloatGauge("bar", nil)
+
+	assert.True(t, fc.registerFloatCounterCalled)
+	assert.True(t, fc.registerFloatGaugeCalled)
+	assert.False(t, fc.registerCounterCalled)
+
+	assert.True(t, ic.registerCounterCalled)
+	assert.True(t, ic.registerGaugeCalled)
+}
@@ -2,20 +2,30 @@ package stats
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 
 	"github.com/upfluence/stats/internal/hash"
 )
 
-var globalIncarnationRegistry = &incarnationRegistry{key: "incarnation", counters: make(map[counterKey]uint)}
+var globalIncarnationRegistry = newIncarnationRegistry("incarnation")
 
 type incarnationRegistry struct {
 	countersMu sync.Mutex
 	counters   map[counterKey]uint
+	live       map[uint64]map[counterKey]map[uint]struct{}
 	key        string
 }
 
+func newIncarnationRegistry(k string) *incarnationRegistry {
+	return &incarnationRegistry{
+		key:      k,
+		counters: make(map[counterKey]uint),
+		live:     make(map[uint64]map[counterKey]map[uint]struct{}),
+	}
+}
+
 func (ir *incarnationRegistry) next(ck counterKey) uint {
 	ir.countersMu.Lock()
 	defer ir.countersMu.Unlock()
@@ -33,6 +43,59 @@ func (ir *incarnationRegistry) next(ck counterKey) uint {
 	return next
 }
 
+func (ir *incarnationRegistry) track(ck counterKey) uint {
+	n := ir.next(ck)
+
+	ir.countersMu.Lock()
+	defer ir.countersMu.Unlock()
+
+	lvs, ok := ir.live[ck.keySum]
+
+	if !ok {
+		lvs = make(map[counterKey]map[uint]struct{})
+		ir.live[ck.keySum] = lvs
+	}
+
+	is, ok := lvs[ck]
+
+	if !ok {
+		is = make(map[uint]struct{})
+		lvs[ck] = is
+	}
+
+	is[n] = struct{}{}
+
+	return n
+}
+
+func (ir *incarnationRegistry) release(ck counterKey) []uint {
+	ir.countersMu.Lock()
+	defer ir.countersMu.Unlock()
+
+	lvs := ir.live[ck.keySum]
+	is := make([]uint, 0, len(lvs[ck]))
+
+	for i := range lvs[ck] {
+		is = append(is, i)
+	}
+
+	delete(lvs, ck)
+
+	if len(lvs) == 0 {
+		delete(ir.live, ck.keySum)
+	}
+
+	sort.Slice(is, func(i, j int) bool { return is[i] < is[j] })
+
+	return is
+}
+
+func (ir *incarnationRegistry) releaseAll(ck counterKey) {
+	ir.countersMu.Lock()
+	delete(ir.live, ck.keySum)
+	ir.countersMu.Unlock()
+}
+
 type counterKey struct {
 	keySum  uint64
 	tagsSum uint64
@@ -99,7 +162,7 @@ func GlobalIncarnationScope(sc Scope) Scope {
 func LocalIncarnationScope(sc Scope, k string) Scope {
 	return newMultiIncarnationScope(
 		sc,
-		&incarnationRegistry{key: k, counters: make(map[counterKey]uint)},
+		newIncarnationRegistry(k),
 	)
 }
 
@@ -128,6 +191,9 @@ func (mis *multiIncarnationScope) RootScope() Scope {
 
 type abstractVector[T any] interface {
 	WithLabels(...string) T
+	Bind(...string) T
+	Delete(...string) bool
+	Reset()
 }
 
 type multiIncarnationVector[T any] struct {
@@ -138,7 +204,7 @@ type multiIncarnationVector[T any] struct {
 	registry   *incarnationRegistry
 }
 
-func (micv *multiIncarnationVector[T]) WithLabels(vs ...string) T {
+func (micv *multiIncarnationVector[T]) counterKey(vs []string) counterKey {
 	if len(vs) != len(micv.ls) {
 		panic("wrong number of label values")
 	}
@@ -149,40 +215,134 @@ func (micv *multiIncarnationVector[T]) WithLabels(vs ...string) T {
 		ck = ck.appendTag(l, vs[i])
 	}
 
-	return micv.cv.WithLabels(
-		append(
-			vs,
-			strconv.Itoa(int(micv.registry.next(ck))),
-		)...,
-	)
+	return ck
 }
 
-func (mis *multiIncarnationScope) Counter(k string) Counter {
-	return mis.CounterVector(k, nil).WithLabels()
+func (micv *multiIncarnationVector[T]) incarnationValues(vs []string) []string {
+	ck := micv.counterKey(vs)
+
+	return append(vs, strconv.Itoa(int(micv.registry.track(ck))))
+}
+
+func (micv *multiIncarnationVector[T]) WithLabels(vs ...string) T {
+	return micv.cv.WithLabels(micv.incarnationValues(vs)...)
+}
+
+func (micv *multiIncarnationVector[T]) Bind(vs ...string) T {
+	return micv.cv.Bind(micv.incarnationValues(vs)...)
 }
 
-func (mis *multiIncarnationScope) CounterVector(k string, ls []string) CounterVector {
+// Delete removes every live incarnation of the series, the incarnations keep
+// increasing if the series is created again.
+func (micv *multiIncarnationVector[T]) Delete(vs ...string) bool {
+	var deleted bool
+
+	for _, i := range micv.registry.release(micv.counterKey(vs)) {
+		if micv.cv.Delete(append(vs[:len(vs):len(vs)], strconv.Itoa(int(i)))...) {
+			deleted = true
+		}
+	}
+
+	return deleted
+}
+
+func (micv *multiIncarnationVector[T]) Reset() {
+	micv.registry.releaseAll(micv.currentKey)
+	micv.cv.Reset()
+}
+
+func (mis *multiIncarnationScope) Counter(k string, opts ...MetricOption) Counter {
+	return mis.CounterVector(k, nil, opts...).WithLabels()
+}
+
+func (mis *multiIncarnationScope) CounterVector(k string, ls []string, opts ...MetricOption) CounterVector {
 	return &multiIncarnationVector[Counter]{
-		cv:         mis.scope.CounterVector(k, append(ls, mis.registry.key)),
+		cv:         mis.scope.CounterVector(k, append(ls, mis.registry.key), opts...),
 		ls:         ls,
 		currentKey: mis.currentKey.add(k, nil),
 		registry:   mis.registry,
 	}
 }
 
-func (mis *multiIncarnationScope) Gauge(k string) Gauge {
-	return mis.GaugeVector(k, nil).WithLabels()
+func (mis *multiIncarnationScope) Gauge(k string, opts ...MetricOption) Gauge {
+	return mis.GaugeVector(k, nil, opts...).WithLabels()
 }
 
-func (mis *multiIncarnationScope) GaugeVector(k string, ls []string) GaugeVector {
+func (mis *multiIncarnationScope) GaugeVector(k string, ls []string, opts ...MetricOption) GaugeVector {
 	return &multiIncarnationVector[Gauge]{
-		cv:         mis.scope.GaugeVector(k, append(ls, mis.registry.key)),
+		cv:         mis.scope.GaugeVector(k, append(ls, mis.registry.key), opts...),
 		ls:         ls,
 		currentKey: mis.currentKey.add(k, nil),
 		registry:   mis.registry,
 	}
 }
 
+func (mis *multiIncarnationScope) FloatCounter(k string, opts ...MetricOption) FloatCounter {
+	return mis.FloatCounterVector(k, nil, opts...).WithLabels()
+}
+
+func (mis *multiIncarnationScope) FloatCounterVector(k string, ls []string, opts ...MetricOption) FloatCounterVector {
+	return &multiIncarnationVector[FloatCounter]{
+		cv:         mis.scope.FloatCounterVector(k, append(ls, mis.registry.key), opts...),
+		ls:         ls,
+		currentKey: mis.currentKey.add(k, nil),
+		registry:   mis.registry,
+	}
+}
+
+func (mis *multiIncarnationScope) FloatGauge(k string, opts ...MetricOption) FloatGauge {
+	return mis.FloatGaugeVector(k, nil, opts...).WithLabels()
+}
+
+func (mis *multiIncarnationScope) FloatGaugeVector(k string, ls []string, opts ...MetricOption) FloatGaugeVector {
+	return &multiIncarnationVector[FloatGauge]{
+		cv:         mis.scope.FloatGaugeVector(k, append(ls, mis.registry.key), opts...),
+		ls:         ls,
+		currentKey: mis.currentKey.add(k, nil),
+		registry:   mis.registry,
+	}
+}
+
+func (mis *multiIncarnationScope) incarnationFunc(k string, fn func() []LabeledInt64) func() []LabeledInt64 {
+	incarnation := strconv.Itoa(int(mis.registry.next(mis.currentKey.add(k, nil))))
+
+	return func() []LabeledInt64 {
+		lvs := fn()
+
+		for i, lv := range lvs {
+			lvs[i].Values = append(lv.Values[:len(lv.Values):len(lv.Values)], incarnation)
+		}
+
+		return lvs
+	}
+}
+
+func (mis *multiIncarnationScope) CounterFunc(k string, fn func() int64, opts ...MetricOption) {
+	mis.CounterVectorFunc(k, nil, scalarInt64Func(fn), opts...)
+}
+
+func (mis *multiIncarnationScope) CounterVectorFunc(k string, ls []string, fn func() []LabeledInt64, opts ...MetricOption) {
+	mis.scope.CounterVectorFunc(
+		k,
+		append(ls, mis.registry.key),
+		mis.incarnationFunc(k, fn),
+		opts...,
+	)
+}
+
+func (mis *multiIncarnationScope) GaugeFunc(k string, fn func() int64, opts ...MetricOption) {
+	mis.GaugeVectorFunc(k, nil, scalarInt64Func(fn), opts...)
+}
+
+func (mis *multiIncarnationScope) GaugeVectorFunc(k string, ls []string, fn func() []LabeledInt64, opts ...MetricOption) {
+	mis.scope.GaugeVectorFunc(
+		k,
+		append(ls, mis.registry.key),
+		mis.incarnationFunc(k, fn),
+		opts...,
+	)
+}
+
 func (mis *multiIncarnationScope) Histogram(k string, opts ...HistogramOption) Histogram {
 	return mis.HistogramVector(k, nil, opts...).WithLabels()
 }
@@ -195,3 +355,16 @@ func (mis *multiIncarnationScope) HistogramVector(k string, ls []string, opts ..
 		registry:   mis.registry,
 	}
 }
+
+func (mis *multiIncarnationScope) Summary(k string, opts ...SummaryOption) Summary {
+	return mis.SummaryVector(k, nil, opts...).WithLabels()
+}
+
+func (mis *multiIncarnationScope) SummaryVector(k string, ls []string, opts ...SummaryOption) SummaryVector {
+	return &multiIncarnationVector[Summary]{
+		cv:         mis.scope.SummaryVector(k, append(ls, mis.registry.key), opts...),
+		ls:         ls,
+		currentKey: mis.currentKey.add(k, nil),
+		registry:   mis.registry,
+	}
+}
@@ -41,3 +41,29 @@ func TestMultiIncarnationScope(t *testing.T) {
 		c.Get().Counters,
 	)
 }
+
+func TestMultiIncarnationLiveTracking(t *testing.T) {
+	r := newIncarnationRegistry("incarnation")
+	cv := newMultiIncarnationScope(RootScope(NewStaticCollector()), r).CounterVector(
+		"foo",
+		[]string{"bar"},
+	)
+
+	for i := 0; i < 3; i++ {
+		cv.Bind("buz").Inc()
+		cv.Bind("biz").Inc()
+		cv.Delete("buz")
+	}
+
+	assert.Len(t, r.live, 1)
+	assert.Len(t, r.live[newCounterKey().add("foo", nil).keySum], 1)
+
+	ck := newCounterKey().add("foo", nil).appendTag("bar", "biz")
+
+	assert.Equal(t, uint(3), r.track(ck))
+	assert.Equal(t, []uint{0, 1, 2, 3}, r.release(ck))
+
+	cv.Reset()
+
+	assert.Empty(t, r.live)
+}
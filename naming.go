@@ -0,0 +1,222 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidName is reported when a metric or label name contains
+	// characters other than ASCII letters, digits, underscores and, for the
+	// metric names, colons, or when it starts with a digit or is empty.
+	ErrInvalidName = errors.New("invalid name")
+
+	// ErrReservedLabelName is reported when a label name is reserved by the
+	// exposition formats, such as le, quantile or the names starting with
+	// two underscores.
+	ErrReservedLabelName = errors.New("reserved label name")
+
+	// ErrDuplicateLabelName is reported when a metric has the same label
+	// twice, once sanitized.
+	ErrDuplicateLabelName = errors.New("duplicate label name")
+)
+
+var reservedLabelNames = map[string]struct{}{"le": {}, "quantile": {}}
+
+// NameError is the error reported when the naming policy of the root scope
+// rejects the name of a metric or of one of its labels. Scope is the
+// namespace of the scope registering the metric and Name the name of the
+// metric, Label is set if the name of a label was rejected.
+type NameError struct {
+	Scope string
+	Name  string
+	Label string
+
+	Err error
+}
+
+func (ne *NameError) Error() string {
+	if ne.Label != "" {
+		return fmt.Sprintf(
+			"%s: label %q rejected in scope %q: %v",
+			ne.Name,
+			ne.Label,
+			ne.Scope,
+			ne.Err,
+		)
+	}
+
+	return fmt.Sprintf("%s: metric name rejected in scope %q: %v", ne.Name, ne.Scope, ne.Err)
+}
+
+func (ne *NameError) Unwrap() error { return ne.Err }
+
+// NamingPolicy validates the metric and label names of a root scope, it
+// returns the name to use, which may be a sanitized version of the given
+// one, or an error if the name is rejected.
+type NamingPolicy interface {
+	MetricName(string) (string, error)
+	LabelName(string) (string, error)
+}
+
+// WithNamingPolicy creates a RootScopeOption applying the naming policy to the
+// metrics registered through the root scope. The rejected names are reported
+// as *NameError, by panicking unless an error handler is set.
+//
+// The names are not validated by default.
+func WithNamingPolicy(p NamingPolicy) RootScopeOption {
+	return func(rs *rootScope) { rs.naming = p }
+}
+
+// ValidatingNamingPolicy rejects the invalid metric and label names and the
+// reserved label names.
+var ValidatingNamingPolicy NamingPolicy = namingPolicy{}
+
+// SanitizingNamingPolicy creates a NamingPolicy replacing the invalid
+// characters of the names by underscores and prefixing the names starting
+// with a digit by prefix, which must be a valid name. The reserved label
+// names are still rejected.
+func SanitizingNamingPolicy(prefix string) NamingPolicy {
+	return namingPolicy{sanitize: true, prefix: prefix}
+}
+
+type namingPolicy struct {
+	sanitize bool
+	prefix   string
+}
+
+func isNameRune(r rune, i int, colon bool) bool {
+	return r == '_' ||
+		(r >= 'a' && r <= 'z') ||
+		(r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9' && i > 0) ||
+		(r == ':' && colon)
+}
+
+func (np namingPolicy) name(n string, colon bool) (string, error) {
+	if n == "" {
+		return "", ErrInvalidName
+	}
+
+	var (
+		b     strings.Builder
+		valid = true
+	)
+
+	for i, r := range n {
+		if isNameRune(r, i, colon) {
+			b.WriteRune(r)
+			continue
+		}
+
+		valid = false
+
+		if i == 0 && r >= '0' && r <= '9' {
+			b.WriteString(np.prefix)
+			b.WriteRune(r)
+			continue
+		}
+
+		b.WriteByte('_')
+	}
+
+	if valid {
+		return n, nil
+	}
+
+	if !np.sanitize {
+		return "", ErrInvalidName
+	}
+
+	s := b.String()
+
+	if r, _ := utf8.DecodeRuneInString(s); isNameRune(r, 0, colon) {
+		return s, nil
+	}
+
+	return "_" + b.String(), nil
+}
+
+func (np namingPolicy) MetricName(n string) (string, error) {
+	return np.name(n, true)
+}
+
+func (np namingPolicy) LabelName(l string) (string, error) {
+	l, err := np.name(l, false)
+
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := reservedLabelNames[l]; ok || strings.HasPrefix(l, "__") {
+		return "", ErrReservedLabelName
+	}
+
+	return l, nil
+}
+
+// applyNamingPolicy applies the naming policy to the metric n, registered by
+// the scope ns, and to its labels.
+func (rs *rootScope) applyNamingPolicy(ns, n string, ls []string) (string, []string, error) {
+	if rs.naming == nil {
+		return n, ls, nil
+	}
+
+	mn, err := rs.naming.MetricName(n)
+
+	if err != nil {
+		return "", nil, &NameError{Scope: ns, Name: n, Err: err}
+	}
+
+	var (
+		res  = make([]string, len(ls))
+		seen = make(map[string]struct{}, len(ls))
+	)
+
+	for i, l := range ls {
+		ln, err := rs.naming.LabelName(l)
+
+		if err != nil {
+			return "", nil, &NameError{Scope: ns, Name: n, Label: l, Err: err}
+		}
+
+		if _, ok := seen[ln]; ok {
+			return "", nil, &NameError{Scope: ns, Name: n, Label: l, Err: ErrDuplicateLabelName}
+		}
+
+		seen[ln] = struct{}{}
+		res[i] = ln
+	}
+
+	return mn, res, nil
+}
+
+// assertDerivedNames returns an error if the naming policy rejects one of the
+// names the collectors derive from the metric n of the given type by
+// appending the suffixes, such as the _count and _sum of a summary, or if one
+// of them is already taken.
+func (rs *rootScope) assertDerivedNames(n, kind string, suffixes ...string) error {
+	for _, s := range suffixes {
+		if rs.naming != nil {
+			if _, err := rs.naming.MetricName(n + s); err != nil {
+				return &NameError{Name: n + s, Err: err}
+			}
+		}
+
+		if err := rs.assertMetricUniqueness(n+s, kind); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reserveDerivedNames prevents the names derived from the registered metric
+// n of the given type from being registered by other metrics.
+func (rs *rootScope) reserveDerivedNames(n, kind string, suffixes ...string) {
+	for _, s := range suffixes {
+		rs.derived[n+s] = kind
+	}
+}
@@ -0,0 +1,207 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		policy NamingPolicy
+		metric string
+		labels []string
+
+		wantMetric string
+		wantLabels []string
+		wantErr    error
+	}{
+		{
+			name:       "valid names",
+			policy:     ValidatingNamingPolicy,
+			metric:     "foo:bar_baz",
+			labels:     []string{"buz", "_biz2"},
+			wantMetric: "foo:bar_baz",
+			wantLabels: []string{"buz", "_biz2"},
+		},
+		{
+			name:    "invalid metric name",
+			policy:  ValidatingNamingPolicy,
+			metric:  "my-svc_requests.total",
+			wantErr: ErrInvalidName,
+		},
+		{
+			name:    "invalid label name",
+			policy:  ValidatingNamingPolicy,
+			metric:  "foo",
+			labels:  []string{"a:b"},
+			wantErr: ErrInvalidName,
+		},
+		{
+			name:    "reserved label name",
+			policy:  ValidatingNamingPolicy,
+			metric:  "foo",
+			labels:  []string{"le"},
+			wantErr: ErrReservedLabelName,
+		},
+		{
+			name:       "sanitized names",
+			policy:     SanitizingNamingPolicy("x"),
+			metric:     "my-svc_requests.total",
+			labels:     []string{"1st", "http.method"},
+			wantMetric: "my_svc_requests_total",
+			wantLabels: []string{"x1st", "http_method"},
+		},
+		{
+			name:       "sanitized names without prefix",
+			policy:     SanitizingNamingPolicy(""),
+			metric:     "2xx",
+			wantMetric: "_2xx",
+			wantLabels: []string{},
+		},
+		{
+			name:       "sanitized non ASCII names",
+			policy:     SanitizingNamingPolicy(""),
+			metric:     "évènement",
+			labels:     []string{"été"},
+			wantMetric: "_v_nement",
+			wantLabels: []string{"_t_"},
+		},
+		{
+			name:    "sanitized reserved label name",
+			policy:  SanitizingNamingPolicy(""),
+			metric:  "foo",
+			labels:  []string{"__name__"},
+			wantErr: ErrReservedLabelName,
+		},
+		{
+			name:    "duplicate sanitized label name",
+			policy:  SanitizingNamingPolicy(""),
+			metric:  "foo",
+			labels:  []string{"a-b", "a.b"},
+			wantErr: ErrDuplicateLabelName,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				rs = rootScope{naming: tt.policy}
+
+				n, ls, err = rs.applyNamingPolicy("", tt.metric, tt.labels)
+			)
+
+			assert.True(t, errors.Is(err, tt.wantErr), "got: %v", err)
+			assert.Equal(t, tt.wantMetric, n)
+			assert.Equal(t, tt.wantLabels, ls)
+		})
+	}
+}
+
+func TestNamingPolicyScope(t *testing.T) {
+	var (
+		errs []error
+
+		c = NewStaticCollector()
+		s = RootScope(
+			c,
+			WithNamingPolicy(ValidatingNamingPolicy),
+			WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		).Scope("my-svc", nil)
+	)
+
+	s.Counter("requests").Inc()
+	s.RootScope().CounterVector("requests", []string{"quantile"}).WithLabels("1").Inc()
+	s.RootScope().Gauge("requests").Update(1)
+
+	assert.Equal(
+		t,
+		[]error{
+			&NameError{Scope: "my-svc", Name: "my-svc_requests", Err: ErrInvalidName},
+			&NameError{Scope: "", Name: "requests", Label: "quantile", Err: ErrReservedLabelName},
+		},
+		errs,
+	)
+
+	assert.Equal(
+		t,
+		Snapshot{Gauges: []Int64Snapshot{{Name: "requests", Labels: map[string]string{}, Value: 1}}},
+		c.Get(),
+	)
+
+	assert.PanicsWithError(
+		t,
+		`my-svc_requests: metric name rejected in scope "my-svc": invalid name`,
+		func() {
+			RootScope(NewStaticCollector(), WithNamingPolicy(ValidatingNamingPolicy)).
+				Scope("my-svc", nil).
+				Counter("requests")
+		},
+	)
+}
+
+type rejectingNamingPolicy []string
+
+func (p rejectingNamingPolicy) MetricName(n string) (string, error) {
+	for _, r := range p {
+		if n == r {
+			return "", ErrInvalidName
+		}
+	}
+
+	return n, nil
+}
+
+func (rejectingNamingPolicy) LabelName(l string) (string, error) { return l, nil }
+
+func TestNamingPolicyDerivedNames(t *testing.T) {
+	var (
+		errs []error
+
+		c = NewStaticCollector()
+		s = RootScope(
+			c,
+			WithNamingPolicy(
+				rejectingNamingPolicy{
+					"foo_max",
+					"bar_sum",
+					"biz_invalid_observations",
+					CardinalityOverflowMetric,
+				},
+			),
+			WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		)
+	)
+
+	s.Histogram("foo", TrackExtrema(time.Minute)).Record(1)
+	s.Summary("bar").Record(1)
+	s.Histogram(
+		"biz",
+		ObservationRange(0, math.Inf(0)),
+		OnInvalidObservation(CountInvalidObservations),
+	).Record(-1)
+
+	cv := s.CounterVector("buz", []string{"a"}, WithMaxSeries(1))
+
+	cv.WithLabels("1").Inc()
+	cv.WithLabels("2").Inc()
+
+	assert.Equal(
+		t,
+		[]error{
+			&NameError{Name: "foo_max", Err: ErrInvalidName},
+			&NameError{Name: "bar_sum", Err: ErrInvalidName},
+			&NameError{Name: "biz_invalid_observations", Err: ErrInvalidName},
+			&NameError{Name: CardinalityOverflowMetric, Err: ErrInvalidName},
+		},
+		errs,
+	)
+
+	sn := c.Get()
+
+	assert.Empty(t, sn.Summaries)
+	assert.Empty(t, sn.Histograms)
+	assert.Len(t, sn.Counters, 2)
+}
@@ -0,0 +1,294 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// MinNativeSchema is the lowest resolution schema of native histograms,
+	// each bucket being 65536 times larger than the previous one.
+	MinNativeSchema = -4
+
+	// MaxNativeSchema is the highest resolution schema of native histograms,
+	// each bucket being about 0.27% larger than the previous one.
+	MaxNativeSchema = 8
+
+	// DefaultNativeZeroThreshold is the default width of the zero bucket.
+	DefaultNativeZeroThreshold = 2.938735877055719e-39
+
+	defaultNativeMaxBuckets = 160
+)
+
+// nativeBounds holds, for each positive schema, the lower bounds of the
+// buckets within an octave as returned as fraction by math.Frexp.
+var nativeBounds = func() [][]float64 {
+	var res = make([][]float64, MaxNativeSchema+1)
+
+	for s := 1; s <= MaxNativeSchema; s++ {
+		n := 1 << s
+		res[s] = make([]float64, n)
+
+		for i := 0; i < n; i++ {
+			res[s][i] = math.Exp2(float64(i)/float64(n)) / 2
+		}
+	}
+
+	return res
+}()
+
+// NativeHistogramValue represents the state of a native histogram, using
+// exponential sparse buckets. The bucket of index i contains the values in
+// (base^(i-1), base^i] where base is 2^(2^-Schema), the negative buckets
+// mirror the positive ones.
+type NativeHistogramValue struct {
+	Schema        int32
+	ZeroThreshold float64
+	ZeroCount     int64
+
+	PositiveBuckets map[int]int64
+	NegativeBuckets map[int]int64
+}
+
+// NativeBuckets creates a HistogramOption tracking the observations in
+// exponential sparse buckets of the given schema, from MinNativeSchema to
+// MaxNativeSchema, in addition to the classic buckets. The schema is
+// automatically decreased when the number of buckets exceeds the limit (160
+// by default, see NativeMaxBuckets).
+func NativeBuckets(schema int32) HistogramOption {
+	return histogramOptionFunc(func(hv *histogramVector) {
+		if schema < MinNativeSchema {
+			schema = MinNativeSchema
+		}
+
+		if schema > MaxNativeSchema {
+			schema = MaxNativeSchema
+		}
+
+		if hv.native == nil {
+			hv.native = &nativeOptions{
+				zeroThreshold: DefaultNativeZeroThreshold,
+				maxBuckets:    defaultNativeMaxBuckets,
+			}
+		}
+
+		hv.native.schema = schema
+	})
+}
+
+// ExponentialSparseBuckets is an alias of NativeBuckets.
+func ExponentialSparseBuckets(schema int32) HistogramOption {
+	return NativeBuckets(schema)
+}
+
+// NativeMaxBuckets configures the number of native buckets above which the
+// resolution of the histogram is reduced. It has no effect without
+// NativeBuckets.
+func NativeMaxBuckets(n int) HistogramOption {
+	return histogramOptionFunc(func(hv *histogramVector) {
+		if hv.native != nil {
+			hv.native.maxBuckets = n
+		}
+	})
+}
+
+// NativeZeroThreshold configures the width of the zero bucket, observations
+// whose absolute value is lower or equal are counted in the zero bucket.
+// It has no effect without NativeBuckets.
+func NativeZeroThreshold(t float64) HistogramOption {
+	return histogramOptionFunc(func(hv *histogramVector) {
+		if hv.native != nil {
+			hv.native.zeroThreshold = t
+		}
+	})
+}
+
+type nativeOptions struct {
+	schema        int32
+	zeroThreshold float64
+	maxBuckets    int
+}
+
+type nativeHistogram struct {
+	zeroThreshold float64
+	maxBuckets    int
+
+	mu        sync.Mutex
+	schema    int32
+	zeroCount int64
+	positive  map[int]int64
+	negative  map[int]int64
+}
+
+func newNativeHistogram(opts *nativeOptions) *nativeHistogram {
+	return &nativeHistogram{
+		zeroThreshold: opts.zeroThreshold,
+		maxBuckets:    opts.maxBuckets,
+		schema:        opts.schema,
+		positive:      make(map[int]int64),
+		negative:      make(map[int]int64),
+	}
+}
+
+// nativeBucketIndex returns the index of the bucket of the positive value v,
+// +Inf is counted in the bucket of the highest finite value.
+func nativeBucketIndex(v float64, schema int32) int {
+	if math.IsInf(v, 1) {
+		v = math.MaxFloat64
+	}
+
+	frac, exp := math.Frexp(v)
+
+	if schema > 0 {
+		bounds := nativeBounds[schema]
+		return sort.SearchFloat64s(bounds, frac) + (exp-1)*len(bounds)
+	}
+
+	key := exp
+
+	if frac == .5 {
+		key--
+	}
+
+	offset := (1 << -schema) - 1
+
+	return (key + offset) >> -schema
+}
+
+func (nh *nativeHistogram) record(v float64, n int64) {
+	nh.mu.Lock()
+	nh.recordLocked(v, n)
+	nh.mu.Unlock()
+}
+
+func (nh *nativeHistogram) recordMany(vs []float64) {
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+
+	for _, v := range vs {
+		nh.recordLocked(v, 1)
+	}
+}
+
+// recordLocked counts n observations of v, NaN is dropped as it does not
+// belong to any bucket.
+func (nh *nativeHistogram) recordLocked(v float64, n int64) {
+	switch {
+	case math.IsNaN(v):
+		return
+	case math.Abs(v) <= nh.zeroThreshold:
+		nh.zeroCount += n
+		return
+	case v > 0:
+		nh.positive[nativeBucketIndex(v, nh.schema)] += n
+	default:
+		nh.negative[nativeBucketIndex(-v, nh.schema)] += n
+	}
+
+	nh.limitBuckets()
+}
+
+// limitBuckets reduces the resolution until the number of buckets fits the
+// limit or the schema is MinNativeSchema.
+func (nh *nativeHistogram) limitBuckets() {
+	for len(nh.positive)+len(nh.negative) > nh.maxBuckets && nh.schema > MinNativeSchema {
+		nh.positive = downscaleNativeBuckets(nh.positive, 1)
+		nh.negative = downscaleNativeBuckets(nh.negative, 1)
+		nh.schema--
+	}
+}
+
+// mergeInto adds the observations of nh to dst, at the lowest resolution of
+// both, and resets nh to the resulting resolution.
+func (nh *nativeHistogram) mergeInto(dst *nativeHistogram) {
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+
+	if nh.schema < dst.schema {
+		dst.positive = downscaleNativeBuckets(dst.positive, dst.schema-nh.schema)
+		dst.negative = downscaleNativeBuckets(dst.negative, dst.schema-nh.schema)
+		dst.schema = nh.schema
+	}
+
+	for k, v := range downscaleNativeBuckets(nh.positive, nh.schema-dst.schema) {
+		dst.positive[k] += v
+	}
+
+	for k, v := range downscaleNativeBuckets(nh.negative, nh.schema-dst.schema) {
+		dst.negative[k] += v
+	}
+
+	dst.zeroCount += nh.zeroCount
+	dst.limitBuckets()
+
+	nh.schema = dst.schema
+	nh.zeroCount = 0
+	clear(nh.positive)
+	clear(nh.negative)
+}
+
+func (nh *nativeHistogram) value() *NativeHistogramValue {
+	nh.mu.Lock()
+	defer nh.mu.Unlock()
+
+	return &NativeHistogramValue{
+		Schema:          nh.schema,
+		ZeroThreshold:   nh.zeroThreshold,
+		ZeroCount:       nh.zeroCount,
+		PositiveBuckets: copyNativeBuckets(nh.positive),
+		NegativeBuckets: copyNativeBuckets(nh.negative),
+	}
+}
+
+func copyNativeBuckets(bs map[int]int64) map[int]int64 {
+	var res = make(map[int]int64, len(bs))
+
+	for k, v := range bs {
+		res[k] = v
+	}
+
+	return res
+}
+
+// downscaleNativeBuckets merges the buckets to reduce the schema by delta,
+// each new bucket covers 2^delta of the previous ones.
+func downscaleNativeBuckets(bs map[int]int64, delta int32) map[int]int64 {
+	if delta <= 0 {
+		return bs
+	}
+
+	var (
+		res    = make(map[int]int64, len(bs))
+		offset = (1 << delta) - 1
+	)
+
+	for k, v := range bs {
+		res[(k+offset)>>delta] += v
+	}
+
+	return res
+}
+
+// DownscaleNativeHistogram returns a copy of the native histogram value with
+// its resolution reduced to the given schema. It is useful for collectors
+// merging native histograms of different schemas.
+func DownscaleNativeHistogram(v *NativeHistogramValue, schema int32) *NativeHistogramValue {
+	var delta = v.Schema - schema
+
+	if delta < 0 {
+		delta = 0
+		schema = v.Schema
+	}
+
+	return &NativeHistogramValue{
+		Schema:          schema,
+		ZeroThreshold:   v.ZeroThreshold,
+		ZeroCount:       v.ZeroCount,
+		PositiveBuckets: downscaleNativeBuckets(copyNativeBuckets(v.PositiveBuckets), delta),
+		NegativeBuckets: downscaleNativeBuckets(copyNativeBuckets(v.NegativeBuckets), delta),
+	}
+}
@@ -0,0 +1,181 @@
+package stats
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNativeBucketIndex(t *testing.T) {
+	for _, tt := range []struct {
+		v      float64
+		schema int32
+		want   int
+	}{
+		{v: 1, schema: 0, want: 0},
+		{v: 1.5, schema: 0, want: 1},
+		{v: 2, schema: 0, want: 1},
+		{v: .25, schema: 0, want: -2},
+		{v: 1, schema: 3, want: 0},
+		{v: 2, schema: 3, want: 8},
+		{v: 1.1, schema: 3, want: 2},
+		{v: 4, schema: -1, want: 1},
+		{v: 5, schema: -1, want: 2},
+		{v: 1000, schema: -4, want: 1},
+	} {
+		assert.Equal(
+			t,
+			tt.want,
+			nativeBucketIndex(tt.v, tt.schema),
+			"value: %v, schema: %d",
+			tt.v,
+			tt.schema,
+		)
+
+		if tt.schema > MinNativeSchema {
+			var (
+				base  = math.Exp2(math.Exp2(-float64(tt.schema)))
+				upper = math.Pow(base, float64(tt.want))
+			)
+
+			assert.LessOrEqual(t, tt.v, upper*(1+1e-9))
+			assert.Greater(t, tt.v, upper/base)
+		}
+	}
+}
+
+func TestNativeHistogram(t *testing.T) {
+	c := NewStaticCollector()
+	h := RootScope(c).Histogram("foo", StaticBuckets(nil), NativeBuckets(0))
+
+	for _, v := range []float64{0, 1, 1.5, 2, -3, math.NaN()} {
+		h.Record(v)
+	}
+
+	assert.Equal(
+		t,
+		&NativeHistogramValue{
+			Schema:          0,
+			ZeroThreshold:   DefaultNativeZeroThreshold,
+			ZeroCount:       1,
+			PositiveBuckets: map[int]int64{0: 1, 1: 2},
+			NegativeBuckets: map[int]int64{2: 1},
+		},
+		c.Get().Histograms[0].Value.Native,
+	)
+}
+
+func TestNativeHistogramInfinities(t *testing.T) {
+	for _, schema := range []int32{MinNativeSchema, 0, 3, MaxNativeSchema} {
+		assert.Equal(
+			t,
+			nativeBucketIndex(math.MaxFloat64, schema),
+			nativeBucketIndex(math.Inf(1), schema),
+			"schema: %d",
+			schema,
+		)
+	}
+
+	c := NewStaticCollector()
+	h := RootScope(c).Histogram("foo", StaticBuckets(nil), NativeBuckets(0))
+
+	h.Record(math.Inf(1))
+	h.RecordMany([]float64{math.Inf(-1), math.Inf(1)})
+
+	v := c.Get().Histograms[0].Value
+
+	assert.Equal(t, int64(3), v.Count)
+	assert.Equal(
+		t,
+		&NativeHistogramValue{
+			Schema:          0,
+			ZeroThreshold:   DefaultNativeZeroThreshold,
+			PositiveBuckets: map[int]int64{1024: 2},
+			NegativeBuckets: map[int]int64{1024: 1},
+		},
+		v.Native,
+	)
+}
+
+func TestNativeHistogramConsistentSnapshot(t *testing.T) {
+	var (
+		wg sync.WaitGroup
+
+		h = RootScope(NewStaticCollector()).Histogram(
+			"foo",
+			NativeBuckets(MaxNativeSchema),
+			NativeMaxBuckets(8),
+		).(*histogram)
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				h.Record(float64(i*1000 + j))
+				h.RecordMany([]float64{-float64(j), .5})
+			}
+		}(i)
+	}
+
+	for i := 0; i < 100; i++ {
+		v := h.value()
+		count := v.Native.ZeroCount
+
+		for _, c := range v.Native.PositiveBuckets {
+			count += c
+		}
+
+		for _, c := range v.Native.NegativeBuckets {
+			count += c
+		}
+
+		assert.Equal(t, v.Count, count)
+	}
+
+	wg.Wait()
+}
+
+func TestNativeHistogramResolutionReduction(t *testing.T) {
+	c := NewStaticCollector()
+	h := RootScope(c).Histogram(
+		"foo",
+		NativeBuckets(MaxNativeSchema),
+		NativeMaxBuckets(4),
+	)
+
+	for v := 1.; v < 1e6; v *= 1.5 {
+		h.Record(v)
+	}
+
+	nv := c.Get().Histograms[0].Value.Native
+
+	assert.Less(t, nv.Schema, int32(MaxNativeSchema))
+	assert.LessOrEqual(t, len(nv.PositiveBuckets), 4)
+
+	var count int64
+
+	for _, v := range nv.PositiveBuckets {
+		count += v
+	}
+
+	assert.Equal(t, c.Get().Histograms[0].Value.Count, count)
+}
+
+func TestDownscaleNativeHistogram(t *testing.T) {
+	v := DownscaleNativeHistogram(
+		&NativeHistogramValue{
+			Schema:          1,
+			PositiveBuckets: map[int]int64{-1: 1, 0: 2, 1: 3, 2: 4, 3: 5},
+		},
+		0,
+	)
+
+	assert.Equal(t, int32(0), v.Schema)
+	assert.Equal(t, map[int]int64{0: 3, 1: 7, 2: 5}, v.PositiveBuckets)
+}
@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// InvalidObservationsSuffix is appended to the name of the histograms
+// configured with CountInvalidObservations to name the counter of their
+// invalid observations.
+const InvalidObservationsSuffix = "_invalid_observations"
+
+// InvalidObservationPolicy defines how a histogram handles its invalid
+// observations: NaN values and values out of the range configured with
+// ObservationRange.
+type InvalidObservationPolicy uint8
+
+const (
+	// RejectInvalidObservations drops the invalid observations, it is the
+	// default policy.
+	RejectInvalidObservations InvalidObservationPolicy = iota
+
+	// ClampInvalidObservations records the out of range observations as the
+	// closest bound of the range, NaN values are dropped.
+	ClampInvalidObservations
+
+	// CountInvalidObservations drops the invalid observations and counts them
+	// in a counter sharing the labels of the histogram, named after the
+	// histogram suffixed by InvalidObservationsSuffix.
+	CountInvalidObservations
+)
+
+func (p InvalidObservationPolicy) String() string {
+	switch p {
+	case RejectInvalidObservations:
+		return "reject"
+	case ClampInvalidObservations:
+		return "clamp"
+	case CountInvalidObservations:
+		return "count"
+	}
+
+	return fmt.Sprintf("InvalidObservationPolicy(%d)", uint8(p))
+}
+
+// ObservationRange creates a HistogramOption considering the observations
+// lower than min or greater than max invalid, e.g. ObservationRange(0,
+// math.Inf(1)) for durations.
+//
+// ObservationRange panics if min is greater than max or if any is NaN.
+func ObservationRange(min, max float64) HistogramOption {
+	if math.IsNaN(min) || math.IsNaN(max) || min > max {
+		panic(fmt.Sprintf("invalid observation range: [%v, %v]", min, max))
+	}
+
+	return histogramOptionFunc(func(hv *histogramVector) {
+		hv.observations.min = min
+		hv.observations.max = max
+	})
+}
+
+// OnInvalidObservation creates a HistogramOption configuring the policy
+// applied to the invalid observations.
+func OnInvalidObservation(p InvalidObservationPolicy) HistogramOption {
+	return histogramOptionFunc(func(hv *histogramVector) {
+		hv.observations.policy = p
+	})
+}
+
+type observationOptions struct {
+	min    float64
+	max    float64
+	policy InvalidObservationPolicy
+}
+
+var defaultObservationOptions = observationOptions{
+	min: math.Inf(-1),
+	max: math.Inf(1),
+}
+
+// sanitize returns the value to record for the n observations of v, false is
+// returned if the observations must be dropped.
+func (oo *observationOptions) sanitize(v float64, n int64, onInvalid func(int64)) (float64, bool) {
+	if v >= oo.min && v <= oo.max {
+		return v, true
+	}
+
+	switch oo.policy {
+	case ClampInvalidObservations:
+		if v < oo.min {
+			return oo.min, true
+		}
+
+		if v > oo.max {
+			return oo.max, true
+		}
+	case CountInvalidObservations:
+		if onInvalid != nil {
+			onInvalid(n)
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,431 @@
+// Package openmetrics implements a collector rendering the metrics into the
+// Prometheus text exposition format and the OpenMetrics 1.0 format, without
+// depending on the Prometheus client libraries.
+package openmetrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/upfluence/stats"
+)
+
+const (
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+	summaryType   = "summary"
+)
+
+// series is a single labeled value of a family, only the fields matching
+// the type of the family are set.
+type series struct {
+	tags map[string]string
+
+	value float64
+	count int64
+	sum   float64
+
+	buckets   []stats.Bucket
+	quantiles []stats.Quantile
+	extrema   *stats.HistogramExtrema
+
+	created float64
+}
+
+type getter interface {
+	metadata() stats.Metadata
+	series() []*series
+}
+
+type family struct {
+	typ     string
+	extrema bool
+	gs      []getter
+}
+
+// Collector exposes the registered metrics over HTTP in the Prometheus text
+// format or in the OpenMetrics format, depending on the Accept header of the
+// request.
+//
+// The metrics registered under the same name by several root scopes are
+// merged, the values of the series sharing the same labels are added up,
+// except for the quantiles of the summaries where the first ones are kept. The
+// _created samples of the OpenMetrics format hold the time the series was
+// created, or first exposed by the collector if the getter does not record it
+// (see stats.CreatedGetter).
+type Collector struct {
+	mu       sync.Mutex
+	families map[string]*family
+	created  map[string]map[string]float64
+
+	now func() time.Time
+}
+
+// NewCollector creates a collector with no registered metrics.
+func NewCollector() *Collector {
+	return &Collector{
+		families: make(map[string]*family),
+		created:  make(map[string]map[string]float64),
+		now:      time.Now,
+	}
+}
+
+func (c *Collector) Close() error { return nil }
+
+// Handler returns an http.Handler serving the metrics, the OpenMetrics format
+// is used if the request accepts it, the Prometheus text format otherwise.
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			buf bytes.Buffer
+			f   = NegotiateFormat(r.Header)
+		)
+
+		if err := c.Write(&buf, f); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", f.ContentType())
+		buf.WriteTo(w)
+	})
+}
+
+func (c *Collector) register(n, typ string, g getter, extrema bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.families[n]
+
+	if !ok {
+		c.families[n] = &family{typ: typ, extrema: extrema, gs: []getter{g}}
+		return
+	}
+
+	if f.typ != typ {
+		panic(&stats.ConflictError{Name: n, Kind: stats.TypeConflict, Has: typ, Want: f.typ})
+	}
+
+	f.gs = append(f.gs, g)
+}
+
+func (c *Collector) RegisterCounter(n string, g stats.Int64VectorGetter) {
+	c.register(n, counterType, int64Getter{Int64VectorGetter: g, created: true}, false)
+}
+
+func (c *Collector) RegisterGauge(n string, g stats.Int64VectorGetter) {
+	c.register(n, gaugeType, int64Getter{Int64VectorGetter: g}, false)
+}
+
+func (c *Collector) RegisterFloatCounter(n string, g stats.Float64VectorGetter) {
+	c.register(n, counterType, float64Getter{Float64VectorGetter: g, created: true}, false)
+}
+
+func (c *Collector) RegisterFloatGauge(n string, g stats.Float64VectorGetter) {
+	c.register(n, gaugeType, float64Getter{Float64VectorGetter: g}, false)
+}
+
+func (c *Collector) RegisterHistogram(n string, g stats.HistogramVectorGetter) {
+	c.register(n, histogramType, histogramGetter{g}, stats.TracksExtrema(g))
+}
+
+func (c *Collector) RegisterSummary(n string, g stats.SummaryVectorGetter) {
+	c.register(n, summaryType, summaryGetter{g}, false)
+}
+
+// Write renders all the registered metrics in the given format.
+func (c *Collector) Write(w io.Writer, f Format) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		ns  = make([]string, 0, len(c.families))
+		now = float64(c.now().UnixNano()) / 1e9
+		ew  = encoder{w: w, format: f}
+	)
+
+	for n := range c.families {
+		ns = append(ns, n)
+	}
+
+	sort.Strings(ns)
+
+	for _, n := range ns {
+		var (
+			fam = c.families[n]
+			md  = fam.gs[0].metadata()
+			ss  = c.stampSeries(n, fam.typ, mergeSeries(fam.typ, fam.gs), now)
+		)
+
+		sortSeries(ss)
+
+		ew.writeFamily(n, fam.typ, md, ss)
+
+		if fam.extrema {
+			ew.writeExtrema(n, ss)
+		}
+	}
+
+	if f == OpenMetricsFormat {
+		ew.writeString("# EOF\n")
+	}
+
+	return ew.err
+}
+
+// stampSeries sets the creation time of the series not recorded by their
+// getter to the time they were first exposed, the series no longer exposed are
+// forgotten.
+func (c *Collector) stampSeries(n, typ string, ss []*series, now float64) []*series {
+	if typ == gaugeType {
+		return ss
+	}
+
+	var (
+		last = c.created[n]
+		next = make(map[string]float64, len(ss))
+	)
+
+	for _, s := range ss {
+		k := seriesKey(s)
+
+		if s.created == 0 {
+			if t, ok := last[k]; ok {
+				s.created = t
+			} else {
+				s.created = now
+			}
+		}
+
+		next[k] = s.created
+	}
+
+	c.created[n] = next
+
+	return ss
+}
+
+func seriesKey(s *series) string {
+	var b strings.Builder
+
+	for _, l := range sortedLabels(s.tags) {
+		b.WriteString(l.name)
+		b.WriteByte(0)
+		b.WriteString(l.value)
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
+
+func sortSeries(ss []*series) {
+	ks := make(map[*series]string, len(ss))
+
+	for _, s := range ss {
+		ks[s] = seriesKey(s)
+	}
+
+	sort.Slice(ss, func(i, j int) bool { return ks[ss[i]] < ks[ss[j]] })
+}
+
+// mergeSeries adds up the series of the getters sharing the same labels. The
+// quantiles of different summaries cannot be merged, the ones of the first
+// summary are kept while their count and sum are added up.
+func mergeSeries(typ string, gs []getter) []*series {
+	if len(gs) == 1 {
+		return gs[0].series()
+	}
+
+	var (
+		keys []string
+		res  = make(map[string]*series)
+	)
+
+	for _, g := range gs {
+		for _, s := range g.series() {
+			k := seriesKey(s)
+			cur, ok := res[k]
+
+			if !ok {
+				keys = append(keys, k)
+				res[k] = s
+				continue
+			}
+
+			if s.created > 0 && (cur.created == 0 || s.created < cur.created) {
+				cur.created = s.created
+			}
+
+			switch typ {
+			case counterType, gaugeType:
+				cur.value += s.value
+			case histogramType:
+				cur.count += s.count
+				cur.sum += s.sum
+				cur.extrema = mergeExtrema(cur.extrema, s.extrema)
+
+				for i := range cur.buckets {
+					if i < len(s.buckets) {
+						cur.buckets[i].Count += s.buckets[i].Count
+					}
+				}
+			case summaryType:
+				cur.count += s.count
+				cur.sum += s.sum
+			}
+		}
+	}
+
+	ss := make([]*series, len(keys))
+
+	for i, k := range keys {
+		ss[i] = res[k]
+	}
+
+	return ss
+}
+
+func mergeExtrema(x, y *stats.HistogramExtrema) *stats.HistogramExtrema {
+	if x == nil {
+		return y
+	}
+
+	if y == nil {
+		return x
+	}
+
+	return &stats.HistogramExtrema{
+		Min: math.Min(x.Min, y.Min),
+		Max: math.Max(x.Max, y.Max),
+	}
+}
+
+// seriesCreated returns the creation time of the series of g in seconds, 0 is
+// returned if g does not record it.
+func seriesCreated(g interface{}, tags map[string]string) float64 {
+	t, ok := stats.SeriesCreated(g, tags)
+
+	if !ok {
+		return 0
+	}
+
+	return float64(t.UnixNano()) / 1e9
+}
+
+// int64Getter looks up the creation time of the series if created is set,
+// gauges have no _created samples.
+type int64Getter struct {
+	stats.Int64VectorGetter
+
+	created bool
+}
+
+func (g int64Getter) metadata() stats.Metadata {
+	return stats.GetMetadata(g.Int64VectorGetter)
+}
+
+func (g int64Getter) series() []*series {
+	var res []*series
+
+	for _, v := range g.Get() {
+		s := &series{tags: v.Tags, value: float64(v.Value)}
+
+		if g.created {
+			s.created = seriesCreated(g.Int64VectorGetter, v.Tags)
+		}
+
+		res = append(res, s)
+	}
+
+	return res
+}
+
+// float64Getter looks up the creation time of the series if created is set,
+// gauges have no _created samples.
+type float64Getter struct {
+	stats.Float64VectorGetter
+
+	created bool
+}
+
+func (g float64Getter) metadata() stats.Metadata {
+	return stats.GetMetadata(g.Float64VectorGetter)
+}
+
+func (g float64Getter) series() []*series {
+	var res []*series
+
+	for _, v := range g.Get() {
+		s := &series{tags: v.Tags, value: v.Value}
+
+		if g.created {
+			s.created = seriesCreated(g.Float64VectorGetter, v.Tags)
+		}
+
+		res = append(res, s)
+	}
+
+	return res
+}
+
+type histogramGetter struct {
+	stats.HistogramVectorGetter
+}
+
+func (g histogramGetter) metadata() stats.Metadata {
+	return stats.GetMetadata(g.HistogramVectorGetter)
+}
+
+func (g histogramGetter) series() []*series {
+	var res []*series
+
+	for _, v := range g.Get() {
+		res = append(
+			res,
+			&series{
+				tags:    v.Tags,
+				count:   v.Count,
+				sum:     v.Sum,
+				buckets: v.Buckets,
+				extrema: v.Extrema,
+				created: seriesCreated(g.HistogramVectorGetter, v.Tags),
+			},
+		)
+	}
+
+	return res
+}
+
+type summaryGetter struct {
+	stats.SummaryVectorGetter
+}
+
+func (g summaryGetter) metadata() stats.Metadata {
+	return stats.GetMetadata(g.SummaryVectorGetter)
+}
+
+func (g summaryGetter) series() []*series {
+	var res []*series
+
+	for _, v := range g.Get() {
+		res = append(
+			res,
+			&series{
+				tags:      v.Tags,
+				count:     v.Count,
+				sum:       v.Sum,
+				quantiles: v.Quantiles,
+				created:   seriesCreated(g.SummaryVectorGetter, v.Tags),
+			},
+		)
+	}
+
+	return res
+}
@@ -0,0 +1,314 @@
+package openmetrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/stats"
+)
+
+var createdSampleRe = regexp.MustCompile(`(?m)^(\w+_created(?:\{[^}]*\})?) (\S+)$`)
+
+// createdSamples returns the values of the _created samples, keyed by their
+// name and labels.
+func createdSamples(s string) map[string]float64 {
+	var res = make(map[string]float64)
+
+	for _, m := range createdSampleRe.FindAllStringSubmatch(s, -1) {
+		v, _ := strconv.ParseFloat(m[2], 64)
+		res[m[1]] = v
+	}
+
+	return res
+}
+
+// stampCreated replaces the values of the _created samples within [from, to]
+// by t, the creation time of the series not being deterministic.
+func stampCreated(s string, from, to, t time.Time) string {
+	return createdSampleRe.ReplaceAllStringFunc(s, func(l string) string {
+		m := createdSampleRe.FindStringSubmatch(l)
+		v, _ := strconv.ParseFloat(m[2], 64)
+
+		if !inTimeRange(v, from, to) {
+			return l
+		}
+
+		return m[1] + " " + formatFloat64(float64(t.UnixNano())/1e9)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		mutate func(stats.Scope)
+		format Format
+		want   string
+	}{
+		{
+			name:   "no mutation",
+			mutate: func(stats.Scope) {},
+			format: OpenMetricsFormat,
+			want:   "# EOF\n",
+		},
+		{
+			name: "counter",
+			mutate: func(s stats.Scope) {
+				cv := s.CounterVector(
+					"requests_total",
+					[]string{"method", "path"},
+					stats.WithHelp("Number of \"requests\"\nserved"),
+				)
+
+				cv.WithLabels("GET", "/foo\"bar").Add(3)
+				cv.WithLabels("POST", "/").Inc()
+			},
+			format: TextFormat,
+			want: `# HELP requests_total Number of "requests"\nserved
+# TYPE requests_total counter
+requests_total{method="GET",path="/foo\"bar"} 3
+requests_total{method="POST",path="/"} 1
+`,
+		},
+		{
+			name: "openmetrics counter",
+			mutate: func(s stats.Scope) {
+				s.Counter(
+					"requests_total",
+					stats.WithHelp("Number of \"requests\""),
+				).Add(3)
+				s.FloatCounter("cpu_seconds", stats.WithUnit("seconds")).Add(1.5)
+			},
+			format: OpenMetricsFormat,
+			want: `# TYPE cpu_seconds counter
+# UNIT cpu_seconds seconds
+cpu_seconds_total 1.5
+cpu_seconds_created 1.5e+09
+# HELP requests Number of \"requests\"
+# TYPE requests counter
+requests_total 3
+requests_created 1.5e+09
+# EOF
+`,
+		},
+		{
+			name: "gauges",
+			mutate: func(s stats.Scope) {
+				s.Gauge("foo", stats.WithUnit("bytes")).Update(-2)
+				s.FloatGauge("bar").Update(.25)
+			},
+			format: OpenMetricsFormat,
+			want: `# TYPE bar gauge
+bar 0.25
+# TYPE foo gauge
+foo -2
+# EOF
+`,
+		},
+		{
+			name: "histogram",
+			mutate: func(s stats.Scope) {
+				s.HistogramVector(
+					"latency",
+					[]string{"path"},
+					stats.StaticBuckets([]float64{.5, 1}),
+				).WithLabels("/").Record(.75)
+			},
+			format: TextFormat,
+			want: `# TYPE latency histogram
+latency_bucket{path="/",le="0.5"} 0
+latency_bucket{path="/",le="1"} 1
+latency_bucket{path="/",le="+Inf"} 1
+latency_sum{path="/"} 0.75
+latency_count{path="/"} 1
+`,
+		},
+		{
+			name: "openmetrics histogram with extrema",
+			mutate: func(s stats.Scope) {
+				h := s.Histogram(
+					"latency",
+					stats.StaticBuckets([]float64{1}),
+					stats.TrackExtrema(0),
+				)
+
+				h.Record(.75)
+				h.Record(3)
+			},
+			format: OpenMetricsFormat,
+			want: `# TYPE latency histogram
+latency_bucket{le="1.0"} 1
+latency_bucket{le="+Inf"} 2
+latency_sum 3.75
+latency_count 2
+latency_created 1.5e+09
+# HELP latency_min Smallest value observed by latency
+# TYPE latency_min gauge
+latency_min 0.75
+# HELP latency_max Largest value observed by latency
+# TYPE latency_max gauge
+latency_max 3
+# EOF
+`,
+		},
+		{
+			name: "openmetrics summary",
+			mutate: func(s stats.Scope) {
+				s.Summary(
+					"size",
+					stats.SummaryObjectives(map[float64]float64{.5: .05}),
+				).Record(4)
+			},
+			format: OpenMetricsFormat,
+			want: `# TYPE size summary
+size{quantile="0.5"} 4
+size_sum 4
+size_count 1
+size_created 1.5e+09
+# EOF
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				buf bytes.Buffer
+
+				c = NewCollector()
+			)
+
+			c.now = func() time.Time { return time.Unix(1500000000, 0) }
+
+			start := time.Now()
+			tt.mutate(stats.RootScope(c))
+			end := time.Now()
+
+			assert.NoError(t, c.Write(&buf, tt.format))
+			assert.Equal(t, tt.want, stampCreated(buf.String(), start, end, c.now()))
+		})
+	}
+}
+
+func TestMergedRootScopes(t *testing.T) {
+	var (
+		buf bytes.Buffer
+
+		c = NewCollector()
+	)
+
+	for i := 0; i < 2; i++ {
+		s := stats.RootScope(c)
+
+		s.CounterVector("foo", []string{"bar"}).WithLabels("buz").Inc()
+		s.Histogram("baz", stats.StaticBuckets(nil)).Record(1)
+		s.Summary(
+			"biz",
+			stats.SummaryObjectives(map[float64]float64{.5: .01}),
+		).Record(float64(i + 1))
+	}
+
+	assert.NoError(t, c.Write(&buf, TextFormat))
+	assert.Equal(
+		t,
+		`# TYPE baz histogram
+baz_bucket{le="+Inf"} 2
+baz_sum 2
+baz_count 2
+# TYPE biz summary
+biz{quantile="0.5"} 1
+biz_sum 3
+biz_count 2
+# TYPE foo counter
+foo{bar="buz"} 2
+`,
+		buf.String(),
+	)
+}
+
+func TestCreated(t *testing.T) {
+	var (
+		buf bytes.Buffer
+
+		now = time.Unix(1500000000, 0)
+		c   = NewCollector()
+		s   = stats.RootScope(c)
+		cv  = s.CounterVector("foo", []string{"bar"})
+	)
+
+	c.now = func() time.Time { return now }
+
+	t0 := time.Now()
+	cv.WithLabels("a").Inc()
+	t1 := time.Now()
+
+	s.CounterFunc("fiz", func() int64 { return 1 })
+	c.Write(&buf, OpenMetricsFormat)
+
+	now = now.Add(time.Minute)
+	cv.WithLabels("b").Inc()
+	t2 := time.Now()
+	buf.Reset()
+
+	assert.NoError(t, c.Write(&buf, OpenMetricsFormat))
+
+	ss := createdSamples(buf.String())
+
+	assert.Len(t, ss, 3)
+	assert.True(t, inTimeRange(ss[`foo_created{bar="a"}`], t0, t1))
+	assert.True(t, inTimeRange(ss[`foo_created{bar="b"}`], t1, t2))
+	assert.Equal(t, 1.5e9, ss["fiz_created"])
+}
+
+func inTimeRange(v float64, from, to time.Time) bool {
+	return v >= float64(from.UnixNano())/1e9 && v <= float64(to.UnixNano())/1e9
+}
+
+func TestRegisterTypeConflict(t *testing.T) {
+	c := NewCollector()
+
+	stats.RootScope(c).Counter("foo")
+
+	assert.Panics(t, func() { stats.RootScope(c).Gauge("foo") })
+}
+
+func TestHandler(t *testing.T) {
+	c := NewCollector()
+
+	stats.RootScope(c).Gauge("foo").Update(1)
+
+	for _, tt := range []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			contentType: "text/plain; version=0.0.4; charset=utf-8",
+			body:        "# TYPE foo gauge\nfoo 1\n",
+		},
+		{
+			accept:      "application/openmetrics-text;version=1.0.0,text/plain;q=0.5",
+			contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8",
+			body:        "# TYPE foo gauge\nfoo 1\n# EOF\n",
+		},
+	} {
+		var (
+			rec = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		)
+
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+
+		c.Handler().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+		assert.Equal(t, tt.body, rec.Body.String())
+	}
+}
@@ -0,0 +1,290 @@
+package openmetrics
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/upfluence/stats"
+)
+
+// Format is an exposition format of the metrics.
+type Format int
+
+const (
+	// TextFormat is the Prometheus text exposition format, version 0.0.4.
+	TextFormat Format = iota
+
+	// OpenMetricsFormat is the OpenMetrics text format, version 1.0.0.
+	OpenMetricsFormat
+)
+
+// ContentType returns the media type of the format, as sent in the
+// Content-Type header.
+func (f Format) ContentType() string {
+	if f == OpenMetricsFormat {
+		return "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	}
+
+	return "text/plain; version=0.0.4; charset=utf-8"
+}
+
+// NegotiateFormat returns OpenMetricsFormat if the Accept header of the
+// request accepts it, TextFormat otherwise.
+func NegotiateFormat(h http.Header) Format {
+	for _, v := range h.Values("Accept") {
+		for _, mt := range strings.Split(v, ",") {
+			if strings.HasPrefix(strings.TrimSpace(mt), "application/openmetrics-text") {
+				return OpenMetricsFormat
+			}
+		}
+	}
+
+	return TextFormat
+}
+
+var (
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	textHelpReplacer        = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	openMetricsHelpReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+func formatFloat64(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatLabelFloat64 formats the values of the le and quantile labels, the
+// OpenMetrics format expects their canonical representation such as "1.0".
+func (f Format) formatLabelFloat64(v float64) string {
+	s := formatFloat64(v)
+
+	if f == OpenMetricsFormat && !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+
+	return s
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+func sortedLabels(tags map[string]string) []label {
+	var ls = make([]label, 0, len(tags))
+
+	for k, v := range tags {
+		ls = append(ls, label{name: k, value: v})
+	}
+
+	sort.Slice(ls, func(i, j int) bool { return ls[i].name < ls[j].name })
+
+	return ls
+}
+
+type encoder struct {
+	w      io.Writer
+	format Format
+
+	buf []byte
+	err error
+}
+
+func (e *encoder) writeString(s string) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = io.WriteString(e.w, s)
+}
+
+func (e *encoder) writeHeader(n, typ string, md stats.Metadata) {
+	if md.Help != "" {
+		r := textHelpReplacer
+
+		if e.format == OpenMetricsFormat {
+			r = openMetricsHelpReplacer
+		}
+
+		e.writeString("# HELP " + n + " " + r.Replace(md.Help) + "\n")
+	}
+
+	e.writeString("# TYPE " + n + " " + typ + "\n")
+
+	// The unit must be a suffix of the name of the family, it is omitted
+	// otherwise.
+	if e.format == OpenMetricsFormat && md.Unit != "" && strings.HasSuffix(n, "_"+md.Unit) {
+		e.writeString("# UNIT " + n + " " + md.Unit + "\n")
+	}
+}
+
+func (e *encoder) appendLabel(l label) {
+	e.buf = append(e.buf, l.name...)
+	e.buf = append(e.buf, `="`...)
+	e.buf = append(e.buf, labelValueReplacer.Replace(l.value)...)
+	e.buf = append(e.buf, '"')
+}
+
+// writeSample writes a sample line, extra is an additional label, such as le
+// or quantile, appended to the labels of the series if its name is not empty.
+func (e *encoder) writeSample(n string, ls []label, extra label, v string) {
+	e.buf = append(e.buf[:0], n...)
+
+	if len(ls) > 0 || extra.name != "" {
+		e.buf = append(e.buf, '{')
+
+		for i, l := range ls {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+
+			e.appendLabel(l)
+		}
+
+		if extra.name != "" {
+			if len(ls) > 0 {
+				e.buf = append(e.buf, ',')
+			}
+
+			e.appendLabel(extra)
+		}
+
+		e.buf = append(e.buf, '}')
+	}
+
+	e.buf = append(e.buf, ' ')
+	e.buf = append(e.buf, v...)
+	e.buf = append(e.buf, '\n')
+
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf)
+	}
+}
+
+func (e *encoder) writeCreated(n string, ls []label, s *series) {
+	if e.format == OpenMetricsFormat {
+		e.writeSample(n+"_created", ls, label{}, formatFloat64(s.created))
+	}
+}
+
+func (e *encoder) writeFamily(n, typ string, md stats.Metadata, ss []*series) {
+	var name = n
+
+	// The OpenMetrics counters are named without their _total suffix, which
+	// is carried by their samples.
+	if typ == counterType && e.format == OpenMetricsFormat {
+		name = strings.TrimSuffix(n, "_total")
+	}
+
+	e.writeHeader(name, typ, md)
+
+	for _, s := range ss {
+		ls := sortedLabels(s.tags)
+
+		switch typ {
+		case counterType:
+			if e.format == OpenMetricsFormat {
+				e.writeSample(name+"_total", ls, label{}, formatFloat64(s.value))
+				e.writeCreated(name, ls, s)
+			} else {
+				e.writeSample(name, ls, label{}, formatFloat64(s.value))
+			}
+		case gaugeType:
+			e.writeSample(name, ls, label{}, formatFloat64(s.value))
+		case histogramType:
+			e.writeHistogram(name, ls, s)
+		case summaryType:
+			for _, q := range s.quantiles {
+				e.writeSample(
+					name,
+					ls,
+					label{name: "quantile", value: e.format.formatLabelFloat64(q.Quantile)},
+					formatFloat64(q.Value),
+				)
+			}
+
+			e.writeSample(name+"_sum", ls, label{}, formatFloat64(s.sum))
+			e.writeSample(name+"_count", ls, label{}, strconv.FormatInt(s.count, 10))
+			e.writeCreated(name, ls, s)
+		}
+	}
+}
+
+func (e *encoder) writeHistogram(n string, ls []label, s *series) {
+	var (
+		cumulative int64
+		inf        bool
+	)
+
+	for _, b := range s.buckets {
+		cumulative += b.Count
+		inf = math.IsInf(b.UpperBound, 1)
+
+		e.writeSample(
+			n+"_bucket",
+			ls,
+			label{name: "le", value: e.format.formatLabelFloat64(b.UpperBound)},
+			strconv.FormatInt(cumulative, 10),
+		)
+	}
+
+	if !inf {
+		e.writeSample(
+			n+"_bucket",
+			ls,
+			label{name: "le", value: "+Inf"},
+			strconv.FormatInt(s.count, 10),
+		)
+	}
+
+	e.writeSample(n+"_sum", ls, label{}, formatFloat64(s.sum))
+	e.writeSample(n+"_count", ls, label{}, strconv.FormatInt(s.count, 10))
+	e.writeCreated(n, ls, s)
+}
+
+// writeExtrema exposes the extrema of the histogram n as the n_min and n_max
+// gauges.
+func (e *encoder) writeExtrema(n string, ss []*series) {
+	for _, ext := range []struct {
+		suffix string
+		help   string
+		fn     func(*stats.HistogramExtrema) float64
+	}{
+		{
+			suffix: "_min",
+			help:   "Smallest value observed by " + n,
+			fn:     func(x *stats.HistogramExtrema) float64 { return x.Min },
+		},
+		{
+			suffix: "_max",
+			help:   "Largest value observed by " + n,
+			fn:     func(x *stats.HistogramExtrema) float64 { return x.Max },
+		},
+	} {
+		e.writeHeader(n+ext.suffix, gaugeType, stats.Metadata{Help: ext.help})
+
+		for _, s := range ss {
+			if s.extrema != nil {
+				e.writeSample(
+					n+ext.suffix,
+					sortedLabels(s.tags),
+					label{},
+					formatFloat64(ext.fn(s.extrema)),
+				)
+			}
+		}
+	}
+}
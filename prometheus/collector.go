@@ -1,10 +1,12 @@
 package prometheus
 
 import (
+	"errors"
 	"net/http"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/upfluence/stats"
@@ -12,14 +14,70 @@ import (
 
 var DefaultCollector = NewCollector(prometheus.DefaultRegisterer)
 
+type collectorOptions struct {
+	gatherer          prometheus.Gatherer
+	handlerOpts       promhttp.HandlerOpts
+	runtimeCollectors bool
+}
+
+// CollectorOption configures a Collector with custom settings.
+type CollectorOption func(*collectorOptions)
+
+// WithGatherer configures the gatherer served by the handler of the
+// collector. Default is the registerer of the collector if it is also a
+// gatherer, such as a prometheus.Registry, the prometheus default gatherer
+// otherwise.
+func WithGatherer(g prometheus.Gatherer) CollectorOption {
+	return func(opts *collectorOptions) { opts.gatherer = g }
+}
+
+// WithHandlerOpts configures the handler of the collector, e.g. its error
+// handling, compression or timeout.
+func WithHandlerOpts(ho promhttp.HandlerOpts) CollectorOption {
+	return func(opts *collectorOptions) { opts.handlerOpts = ho }
+}
+
+// WithRuntimeCollectors registers the Go runtime and process collectors
+// along with the metrics of the collector. They are already part of the
+// prometheus default registry.
+func WithRuntimeCollectors() CollectorOption {
+	return func(opts *collectorOptions) { opts.runtimeCollectors = true }
+}
+
 type Collector struct {
 	r prometheus.Registerer
+	g prometheus.Gatherer
+
+	handlerOpts promhttp.HandlerOpts
 
 	histogramGettersMu sync.Mutex
 	histogramGetters   map[string]*multiHistogramVectorGetter
 
 	int64GettersMu sync.Mutex
 	int64Getters   map[string]*multiInt64VectorGetter
+
+	float64GettersMu sync.Mutex
+	float64Getters   map[string]*multiFloat64VectorGetter
+
+	summaryGettersMu sync.Mutex
+	summaryGetters   map[string]*multiSummaryVectorGetter
+
+	// registered holds the prometheus collectors registered by metric name,
+	// runtimeCollectors the ones registered by WithRuntimeCollectors.
+	registeredMu      sync.Mutex
+	registered        map[string]registration
+	derived           map[string]string
+	runtimeCollectors []prometheus.Collector
+}
+
+// registration is a prometheus collector registered for a metric, kind is
+// the type of the metric such as "counter" or "histogram", derived the names
+// of the other metrics it exposes, such as the extrema of a histogram. The
+// derived names are mapped to the metric exposing them in Collector.derived.
+type registration struct {
+	kind    string
+	pc      prometheus.Collector
+	derived []string
 }
 
 // NewDefaultCollector returns a collector based on the default prometheus
@@ -30,45 +88,279 @@ func NewDefaultCollector() *Collector {
 	return NewCollector(prometheus.DefaultRegisterer)
 }
 
-func NewCollector(r prometheus.Registerer) *Collector {
+func NewCollector(r prometheus.Registerer, cOpts ...CollectorOption) *Collector {
+	var opts collectorOptions
+
+	for _, opt := range cOpts {
+		opt(&opts)
+	}
+
+	if opts.gatherer == nil {
+		if g, ok := r.(prometheus.Gatherer); ok {
+			opts.gatherer = g
+		} else {
+			opts.gatherer = prometheus.DefaultGatherer
+		}
+	}
+
 	c := &Collector{
 		r:                r,
+		g:                opts.gatherer,
+		handlerOpts:      opts.handlerOpts,
 		histogramGetters: make(map[string]*multiHistogramVectorGetter),
 		int64Getters:     make(map[string]*multiInt64VectorGetter),
+		float64Getters:   make(map[string]*multiFloat64VectorGetter),
+		summaryGetters:   make(map[string]*multiSummaryVectorGetter),
+		registered:       make(map[string]registration),
+		derived:          make(map[string]string),
+	}
+
+	if opts.runtimeCollectors {
+		c.registerRuntimeCollectors()
 	}
 
 	return c
 }
 
-func (c *Collector) Close() error { return nil }
+func (c *Collector) registerRuntimeCollectors() {
+	for _, rc := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		var are prometheus.AlreadyRegisteredError
+
+		err := c.r.Register(rc)
+
+		switch {
+		case err == nil:
+			c.runtimeCollectors = append(c.runtimeCollectors, rc)
+		case !errors.As(err, &are):
+			panic(err)
+		}
+	}
+}
+
+type labeledGetter interface {
+	Labels() []string
+}
+
+func newDesc(n string, g labeledGetter) *prometheus.Desc {
+	help := stats.GetMetadata(g).Help
+
+	if help == "" {
+		help = "no help"
+	}
+
+	return prometheus.NewDesc(n, help, g.Labels(), nil)
+}
+
+// Handler returns an http.Handler serving the metrics of the gatherer of the
+// collector.
 func (c *Collector) Handler() http.Handler {
-	return promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{})
+	return promhttp.HandlerFor(c.g, c.handlerOpts)
 }
 
-func (c *Collector) RegisterHistogram(n string, g stats.HistogramVectorGetter) {
+// Close unregisters from the registerer all the prometheus collectors
+// registered by the collector.
+func (c *Collector) Close() error {
+	// The getters locks are taken before registeredMu, as when registering.
 	c.histogramGettersMu.Lock()
-	mhvg, ok := c.histogramGetters[n]
+	defer c.histogramGettersMu.Unlock()
+
+	c.summaryGettersMu.Lock()
+	defer c.summaryGettersMu.Unlock()
+
+	c.int64GettersMu.Lock()
+	defer c.int64GettersMu.Unlock()
+
+	c.float64GettersMu.Lock()
+	defer c.float64GettersMu.Unlock()
+
+	c.registeredMu.Lock()
+	defer c.registeredMu.Unlock()
+
+	for _, r := range c.registered {
+		c.r.Unregister(r.pc)
+	}
 
-	if !ok {
-		mhvg = &multiHistogramVectorGetter{}
-		c.histogramGetters[n] = mhvg
+	for _, rc := range c.runtimeCollectors {
+		c.r.Unregister(rc)
+	}
+
+	c.registered = make(map[string]registration)
+	c.derived = make(map[string]string)
+	c.runtimeCollectors = nil
+
+	c.histogramGetters = make(map[string]*multiHistogramVectorGetter)
+	c.summaryGetters = make(map[string]*multiSummaryVectorGetter)
+	c.int64Getters = make(map[string]*multiInt64VectorGetter)
+	c.float64Getters = make(map[string]*multiFloat64VectorGetter)
+
+	return nil
+}
+
+// register registers the prometheus collector of the metric n, exposing the
+// derived metrics as well. It panics with a *stats.ConflictError if n or one
+// of the derived names is already exposed by a metric of the collector, or
+// with a *stats.RegistrationError if the registerer rejects the collector.
+func (c *Collector) register(n, kind string, pc prometheus.Collector, derived ...string) {
+	c.registeredMu.Lock()
+	defer c.registeredMu.Unlock()
+
+	for _, dn := range append([]string{n}, derived...) {
+		if k, ok := c.exposedKind(dn); ok {
+			panic(&stats.ConflictError{Name: dn, Kind: stats.TypeConflict, Has: kind, Want: k})
+		}
+	}
+
+	if err := c.r.Register(pc); err != nil {
+		panic(&stats.RegistrationError{Name: n, Err: err})
+	}
+
+	c.registered[n] = registration{kind: kind, pc: pc, derived: derived}
+
+	for _, dn := range derived {
+		c.derived[dn] = n
+	}
+}
+
+// exposedKind returns the kind of the metric exposing the name n, either as
+// its name or as one of its derived names.
+func (c *Collector) exposedKind(n string) (string, bool) {
+	if r, ok := c.registered[n]; ok {
+		return r.kind, true
+	}
+
+	if on, ok := c.derived[n]; ok {
+		return c.registered[on].kind, true
+	}
+
+	return "", false
+}
+
+func (c *Collector) unregister(n string) {
+	c.registeredMu.Lock()
+	defer c.registeredMu.Unlock()
+
+	if r, ok := c.registered[n]; ok {
+		c.r.Unregister(r.pc)
+		delete(c.registered, n)
+
+		for _, dn := range r.derived {
+			delete(c.derived, dn)
+		}
+	}
+}
+
+// Unregister removes the getter registered under the given name, the
+// prometheus collector of the name is unregistered once it has no getter
+// left.
+func (c *Collector) Unregister(n string, g interface{}) {
+	c.histogramGettersMu.Lock()
+
+	if mhvg, ok := c.histogramGetters[n]; ok && mhvg.removeGetter(g) {
+		delete(c.histogramGetters, n)
+		c.unregister(n)
 	}
 
 	c.histogramGettersMu.Unlock()
 
-	mhvg.appendGetter(n, g)
+	c.summaryGettersMu.Lock()
+
+	if msvg, ok := c.summaryGetters[n]; ok && msvg.removeGetter(g) {
+		delete(c.summaryGetters, n)
+		c.unregister(n)
+	}
+
+	c.summaryGettersMu.Unlock()
+
+	c.int64GettersMu.Lock()
+
+	if mivg, ok := c.int64Getters[n]; ok && mivg.removeGetter(g) {
+		delete(c.int64Getters, n)
+		c.unregister(n)
+	}
+
+	c.int64GettersMu.Unlock()
+
+	c.float64GettersMu.Lock()
+
+	if mfvg, ok := c.float64Getters[n]; ok && mfvg.removeGetter(g) {
+		delete(c.float64Getters, n)
+		c.unregister(n)
+	}
+
+	c.float64GettersMu.Unlock()
+}
+
+// removeGetter returns gs without g, the returned slice never shares the
+// backing array of gs so the readers of gs are not affected.
+func removeGetter[T any](gs []T, g interface{}) ([]T, bool) {
+	for i, cg := range gs {
+		if interface{}(cg) == g {
+			return append(gs[:i:i], gs[i+1:]...), true
+		}
+	}
+
+	return gs, false
+}
+
+func (c *Collector) RegisterHistogram(n string, g stats.HistogramVectorGetter) {
+	c.histogramGettersMu.Lock()
+	defer c.histogramGettersMu.Unlock()
+
+	mhvg, ok := c.histogramGetters[n]
 
 	if ok {
+		mhvg.appendGetter(n, g)
 		return
 	}
 
-	c.r.MustRegister(
-		&histogramWrapper{
-			g:    mhvg,
-			n:    n,
-			desc: prometheus.NewDesc(n, "no help", g.Labels(), nil),
-		},
+	mhvg = &multiHistogramVectorGetter{}
+	mhvg.appendGetter(n, g)
+
+	var (
+		hw      = &histogramWrapper{g: mhvg, n: n, desc: newDesc(n, g)}
+		derived []string
 	)
+
+	if stats.TracksExtrema(g) {
+		derived = []string{n + "_min", n + "_max"}
+
+		hw.minDesc = prometheus.NewDesc(
+			n+"_min",
+			"Smallest value observed by "+n,
+			g.Labels(),
+			nil,
+		)
+		hw.maxDesc = prometheus.NewDesc(
+			n+"_max",
+			"Largest value observed by "+n,
+			g.Labels(),
+			nil,
+		)
+	}
+
+	c.register(n, "histogram", hw, derived...)
+	c.histogramGetters[n] = mhvg
+}
+
+func (c *Collector) RegisterSummary(n string, g stats.SummaryVectorGetter) {
+	c.summaryGettersMu.Lock()
+	defer c.summaryGettersMu.Unlock()
+
+	msvg, ok := c.summaryGetters[n]
+
+	if ok {
+		msvg.appendGetter(n, g)
+		return
+	}
+
+	msvg = &multiSummaryVectorGetter{}
+	msvg.appendGetter(n, g)
+
+	c.register(n, "summary", &summaryWrapper{g: msvg, n: n, desc: newDesc(n, g)})
+	c.summaryGetters[n] = msvg
 }
 
 func (c *Collector) RegisterGauge(n string, g stats.Int64VectorGetter) {
@@ -81,27 +373,62 @@ func (c *Collector) RegisterCounter(n string, g stats.Int64VectorGetter) {
 
 func (c *Collector) registerInt64Collector(n string, g stats.Int64VectorGetter, m registrarMode) {
 	c.int64GettersMu.Lock()
+	defer c.int64GettersMu.Unlock()
+
 	mivg, ok := c.int64Getters[n]
 
-	if !ok {
-		mivg = &multiInt64VectorGetter{}
-		c.int64Getters[n] = mivg
+	if ok {
+		mivg.appendGetter(n, m, g)
+		return
 	}
 
-	c.int64GettersMu.Unlock()
-
+	mivg = &multiInt64VectorGetter{}
 	mivg.appendGetter(n, m, g)
 
+	c.register(
+		n,
+		m.String(),
+		&int64Wrapper{
+			g:       mivg,
+			n:       n,
+			desc:    newDesc(n, g),
+			stapler: registrarModeOps[m],
+		},
+	)
+	c.int64Getters[n] = mivg
+}
+
+func (c *Collector) RegisterFloatGauge(n string, g stats.Float64VectorGetter) {
+	c.registerFloat64Collector(n, g, gauge)
+}
+
+func (c *Collector) RegisterFloatCounter(n string, g stats.Float64VectorGetter) {
+	c.registerFloat64Collector(n, g, counter)
+}
+
+func (c *Collector) registerFloat64Collector(n string, g stats.Float64VectorGetter, m registrarMode) {
+	c.float64GettersMu.Lock()
+	defer c.float64GettersMu.Unlock()
+
+	mfvg, ok := c.float64Getters[n]
+
 	if ok {
+		mfvg.appendGetter(n, m, g)
 		return
 	}
 
-	c.r.MustRegister(
-		&int64Wrapper{
-			g:       mivg,
+	mfvg = &multiFloat64VectorGetter{}
+	mfvg.appendGetter(n, m, g)
+
+	c.register(
+		n,
+		"float "+m.String(),
+		&float64Wrapper{
+			g:       mfvg,
 			n:       n,
-			desc:    prometheus.NewDesc(n, "no help", g.Labels(), nil),
+			desc:    newDesc(n, g),
 			stapler: registrarModeOps[m],
 		},
 	)
+	c.float64Getters[n] = mfvg
 }
@@ -1,8 +1,13 @@
 package prometheus
 
 import (
+	"errors"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -109,6 +114,85 @@ func TestCollect(t *testing.T) {
 				)
 			},
 		},
+		{
+			name:   "one float gauge",
+			mutate: func(s stats.Scope) { s.FloatGauge("foo").Update(.5) },
+			introspect: func(t *testing.T, fs []*dto.MetricFamily) {
+				mt := dto.MetricType_GAUGE
+				assert.Equal(
+					t,
+					[]*dto.MetricFamily{
+						&dto.MetricFamily{
+							Name: proto.String("foo"),
+							Help: proto.String("no help"),
+							Type: &mt,
+							Metric: []*dto.Metric{
+								&dto.Metric{Gauge: &dto.Gauge{Value: proto.Float64(.5)}},
+							},
+						},
+					},
+					fs,
+				)
+			},
+		},
+		{
+			name:   "one float counter",
+			mutate: func(s stats.Scope) { s.FloatCounter("foo").Add(1.5) },
+			introspect: func(t *testing.T, fs []*dto.MetricFamily) {
+				mt := dto.MetricType_COUNTER
+				assert.Equal(
+					t,
+					[]*dto.MetricFamily{
+						&dto.MetricFamily{
+							Name: proto.String("foo"),
+							Help: proto.String("no help"),
+							Type: &mt,
+							Metric: []*dto.Metric{
+								&dto.Metric{Counter: &dto.Counter{Value: proto.Float64(1.5)}},
+							},
+						},
+					},
+					fs,
+				)
+			},
+		},
+		{
+			name: "one summary",
+			mutate: func(s stats.Scope) {
+				s.Summary(
+					"foo",
+					stats.SummaryObjectives(map[float64]float64{.5: .05}),
+				).Record(5.)
+			},
+			introspect: func(t *testing.T, fs []*dto.MetricFamily) {
+				mt := dto.MetricType_SUMMARY
+				assert.Equal(
+					t,
+					[]*dto.MetricFamily{
+						&dto.MetricFamily{
+							Name: proto.String("foo"),
+							Help: proto.String("no help"),
+							Type: &mt,
+							Metric: []*dto.Metric{
+								&dto.Metric{
+									Summary: &dto.Summary{
+										SampleCount: proto.Uint64(1),
+										SampleSum:   proto.Float64(5.),
+										Quantile: []*dto.Quantile{
+											&dto.Quantile{
+												Quantile: proto.Float64(.5),
+												Value:    proto.Float64(5.),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					fs,
+				)
+			},
+		},
 		{
 			name: "one labeled counter",
 			mutate: func(s stats.Scope) {
@@ -140,6 +224,78 @@ func TestCollect(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "one counter with help",
+			mutate: func(s stats.Scope) {
+				s.Counter("foo", stats.WithHelp("foo help"), stats.WithUnit("bytes")).Inc()
+			},
+			introspect: func(t *testing.T, fs []*dto.MetricFamily) {
+				mt := dto.MetricType_COUNTER
+				assert.Equal(
+					t,
+					[]*dto.MetricFamily{
+						&dto.MetricFamily{
+							Name: proto.String("foo"),
+							Help: proto.String("foo help"),
+							Type: &mt,
+							Metric: []*dto.Metric{
+								&dto.Metric{Counter: &dto.Counter{Value: proto.Float64(1)}},
+							},
+						},
+					},
+					fs,
+				)
+			},
+		},
+		{
+			name: "one summary with help",
+			mutate: func(s stats.Scope) {
+				s.Summary(
+					"foo",
+					stats.SummaryObjectives(map[float64]float64{}),
+					stats.WithHelp("foo help"),
+				).Record(5.)
+			},
+			introspect: func(t *testing.T, fs []*dto.MetricFamily) {
+				assert.Equal(t, 1, len(fs))
+				assert.Equal(t, "foo help", fs[0].GetHelp())
+			},
+		},
+		{
+			name: "one histogram with extrema",
+			mutate: func(s stats.Scope) {
+				h := s.HistogramVector(
+					"foo",
+					[]string{"bar"},
+					stats.StaticBuckets([]float64{1.}),
+					stats.TrackExtrema(0),
+				).WithLabels("buz")
+
+				h.Record(5.)
+				h.Record(-2.)
+				h.Record(3.)
+			},
+			introspect: func(t *testing.T, fs []*dto.MetricFamily) {
+				assert.Equal(t, 3, len(fs))
+
+				for i, tt := range []struct {
+					name  string
+					value float64
+				}{
+					{name: "foo"},
+					{name: "foo_max", value: 5.},
+					{name: "foo_min", value: -2.},
+				} {
+					assert.Equal(t, tt.name, fs[i].GetName())
+					assert.Equal(t, "bar", fs[i].GetMetric()[0].GetLabel()[0].GetName())
+
+					if tt.value != 0 {
+						assert.Equal(t, dto.MetricType_GAUGE, fs[i].GetType())
+						assert.Equal(t, tt.value, fs[i].GetMetric()[0].GetGauge().GetValue())
+					}
+				}
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			r := prometheus.NewRegistry()
@@ -273,3 +429,417 @@ func TestMultiRegisterHistogram(t *testing.T) {
 		fs,
 	)
 }
+
+func TestNativeHistogram(t *testing.T) {
+	r := prometheus.NewRegistry()
+	c := NewCollector(r)
+
+	for i := 0; i < 2; i++ {
+		h := stats.RootScope(c).Histogram(
+			"foo",
+			stats.StaticBuckets(nil),
+			stats.NativeBuckets(int32(i)),
+		)
+
+		h.Record(1)
+		h.Record(4)
+		h.Record(0)
+	}
+
+	fs, err := r.Gather()
+	assert.Nil(t, err)
+	mt := dto.MetricType_HISTOGRAM
+	assert.Equal(
+		t,
+		[]*dto.MetricFamily{
+			&dto.MetricFamily{
+				Name: proto.String("foo"),
+				Help: proto.String("no help"),
+				Type: &mt,
+				Metric: []*dto.Metric{
+					&dto.Metric{
+						Histogram: &dto.Histogram{
+							SampleCount: proto.Uint64(6),
+							SampleSum:   proto.Float64(10.),
+							Bucket: []*dto.Bucket{
+								&dto.Bucket{
+									CumulativeCount: proto.Uint64(6),
+									UpperBound:      proto.Float64(math.Inf(0)),
+								},
+							},
+							Schema:        proto.Int32(0),
+							ZeroThreshold: proto.Float64(stats.DefaultNativeZeroThreshold),
+							ZeroCount:     proto.Uint64(2),
+							PositiveSpan: []*dto.BucketSpan{
+								&dto.BucketSpan{Offset: proto.Int32(0), Length: proto.Uint32(1)},
+								&dto.BucketSpan{Offset: proto.Int32(1), Length: proto.Uint32(1)},
+							},
+							PositiveDelta: []int64{2, 0},
+						},
+					},
+				},
+			},
+		},
+		fs,
+	)
+}
+
+func TestHandler(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		opts     []CollectorOption
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "private registry",
+			contains: []string{"foo 1"},
+			excludes: []string{"go_goroutines"},
+		},
+		{
+			name:     "runtime collectors",
+			opts:     []CollectorOption{WithRuntimeCollectors()},
+			contains: []string{"foo 1", "go_goroutines"},
+		},
+		{
+			name:     "custom gatherer",
+			opts:     []CollectorOption{WithGatherer(prometheus.NewRegistry())},
+			excludes: []string{"foo 1"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   = prometheus.NewRegistry()
+				c   = NewCollector(r, tt.opts...)
+				rec = httptest.NewRecorder()
+			)
+
+			stats.RootScope(c).Gauge("foo").Update(1)
+
+			c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			for _, s := range tt.contains {
+				assert.Contains(t, rec.Body.String(), s)
+			}
+
+			for _, s := range tt.excludes {
+				assert.NotContains(t, rec.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestRuntimeCollectorsOnDefaultRegistry(t *testing.T) {
+	assert.NotPanics(
+		t,
+		func() { NewCollector(prometheus.DefaultRegisterer, WithRuntimeCollectors()) },
+	)
+}
+
+func TestClose(t *testing.T) {
+	r := prometheus.NewRegistry()
+
+	for i := 0; i < 2; i++ {
+		c := NewCollector(r, WithRuntimeCollectors())
+		s := stats.RootScope(c)
+
+		s.Counter("foo").Inc()
+		s.Histogram("bar").Record(1)
+		s.Summary("biz").Record(1)
+		s.FloatGauge("buz").Update(1)
+
+		fs, err := r.Gather()
+
+		assert.NoError(t, err)
+		assert.Greater(t, len(fs), 4)
+
+		assert.NoError(t, c.Close())
+
+		fs, err = r.Gather()
+
+		assert.NoError(t, err)
+		assert.Empty(t, fs)
+	}
+}
+
+func TestConcurrentClose(t *testing.T) {
+	var (
+		wg sync.WaitGroup
+
+		c = NewCollector(prometheus.NewRegistry())
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				s := stats.RootScope(c)
+
+				s.Counter("foo").Inc()
+				s.Histogram("bar").Record(1)
+				s.Summary("biz").Record(1)
+				s.FloatGauge("buz").Update(1)
+
+				stats.Detach(s)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, c.Close())
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestDetach(t *testing.T) {
+	var (
+		r = prometheus.NewRegistry()
+		c = NewCollector(r)
+
+		s1 = stats.RootScope(c)
+		s2 = stats.RootScope(c)
+	)
+
+	s1.CounterVector("foo", []string{"bar"}).WithLabels("buz").Add(2)
+	s2.CounterVector("foo", []string{"bar"}).WithLabels("buz").Inc()
+	s1.Histogram("biz").Record(1)
+
+	stats.Detach(s1)
+
+	fs, err := r.Gather()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(fs))
+	assert.Equal(t, "foo", fs[0].GetName())
+	assert.Equal(t, 1., fs[0].GetMetric()[0].GetCounter().GetValue())
+
+	stats.Detach(s2)
+
+	fs, err = r.Gather()
+
+	assert.NoError(t, err)
+	assert.Empty(t, fs)
+
+	s1.CounterVector("foo", []string{"bar"}).WithLabels("buz").Inc()
+
+	fs, err = r.Gather()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(fs))
+}
+
+func TestDetachOverflows(t *testing.T) {
+	var (
+		r = prometheus.NewRegistry()
+		s = stats.RootScope(NewCollector(r))
+	)
+
+	for i := 0; i < 2; i++ {
+		cv := s.CounterVector("foo", []string{"bar"}, stats.WithMaxSeries(1))
+
+		cv.WithLabels("buz").Inc()
+		cv.WithLabels("biz").Inc()
+
+		fs, err := r.Gather()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(fs))
+		assert.Equal(t, stats.CardinalityOverflowMetric, fs[1].GetName())
+		assert.Equal(t, 1., fs[1].GetMetric()[0].GetCounter().GetValue())
+
+		stats.Detach(s)
+	}
+}
+
+func TestDetachInvalidObservations(t *testing.T) {
+	var (
+		r = prometheus.NewRegistry()
+		s = stats.RootScope(NewCollector(r))
+
+		h = s.Histogram(
+			"foo",
+			stats.ObservationRange(0, 1),
+			stats.OnInvalidObservation(stats.CountInvalidObservations),
+		)
+	)
+
+	stats.Detach(s)
+
+	h.Record(2)
+
+	fs, err := r.Gather()
+
+	assert.NoError(t, err)
+	assert.Empty(t, fs)
+}
+
+func TestErrorHandler(t *testing.T) {
+	var (
+		errs []error
+
+		r = prometheus.NewRegistry()
+		c = NewCollector(r)
+
+		s1 = stats.RootScope(c)
+		s2 = stats.RootScope(
+			c,
+			stats.WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		)
+	)
+
+	s1.CounterVector("foo", []string{"bar"}).WithLabels("buz").Inc()
+	s2.CounterVector("foo", []string{"biz"}).WithLabels("buz").Add(2)
+	s1.Counter("bar").Inc()
+	s2.Gauge("bar").Update(2)
+	s2.Histogram("bar").Record(2)
+	s2.FloatCounter("bar").Add(2)
+
+	assert.Equal(
+		t,
+		[]error{
+			&stats.ConflictError{
+				Name: "foo",
+				Kind: stats.LabelsConflict,
+				Has:  []string{"biz"},
+				Want: []string{"bar"},
+			},
+			&stats.ConflictError{Name: "bar", Kind: stats.TypeConflict, Has: gauge, Want: counter},
+			&stats.ConflictError{
+				Name: "bar",
+				Kind: stats.TypeConflict,
+				Has:  "histogram",
+				Want: "counter",
+			},
+			&stats.ConflictError{
+				Name: "bar",
+				Kind: stats.TypeConflict,
+				Has:  "float counter",
+				Want: "counter",
+			},
+		},
+		errs,
+	)
+
+	fs, err := r.Gather()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(fs))
+
+	for _, f := range fs {
+		assert.Equal(t, 1., f.GetMetric()[0].GetCounter().GetValue())
+	}
+
+	assert.Panics(t, func() { stats.RootScope(c).Gauge("bar") })
+}
+
+func TestDerivedNamesConflict(t *testing.T) {
+	var (
+		errs []error
+
+		r = prometheus.NewRegistry()
+		s = stats.RootScope(
+			NewCollector(r),
+			stats.WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		)
+	)
+
+	assert.NotPanics(t, func() {
+		s.Histogram("foo", stats.TrackExtrema(time.Minute)).Record(1)
+		s.Gauge("foo_min").Update(1)
+		s.Gauge("bar_max").Update(1)
+		s.Histogram("bar", stats.TrackExtrema(time.Minute)).Record(1)
+	})
+
+	assert.Equal(
+		t,
+		[]error{
+			&stats.ConflictError{
+				Name: "foo_min",
+				Kind: stats.TypeConflict,
+				Has:  "gauge",
+				Want: "histogram",
+			},
+			&stats.ConflictError{
+				Name: "bar_max",
+				Kind: stats.TypeConflict,
+				Has:  "histogram",
+				Want: "gauge",
+			},
+		},
+		errs,
+	)
+
+	fs, err := r.Gather()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(fs))
+}
+
+func TestRegistrationErrors(t *testing.T) {
+	var (
+		errs []error
+
+		r = prometheus.NewRegistry()
+		c = NewCollector(r)
+
+		s1 = stats.RootScope(c)
+		s2 = stats.RootScope(
+			c,
+			stats.WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		)
+	)
+
+	r.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "fiz"}))
+
+	s1.Histogram("foo", stats.TrackExtrema(time.Minute)).Record(1)
+	s1.Gauge("bar_max").Update(1)
+
+	assert.NotPanics(t, func() {
+		s2.Gauge("foo_min").Update(1)
+		s2.Histogram("bar", stats.TrackExtrema(time.Minute)).Record(1)
+		s2.Counter("fiz").Inc()
+	})
+
+	assert.Len(t, errs, 3)
+	assert.Equal(
+		t,
+		[]error{
+			&stats.ConflictError{
+				Name: "foo_min",
+				Kind: stats.TypeConflict,
+				Has:  "gauge",
+				Want: "histogram",
+			},
+			&stats.ConflictError{
+				Name: "bar_max",
+				Kind: stats.TypeConflict,
+				Has:  "histogram",
+				Want: "gauge",
+			},
+		},
+		errs[:2],
+	)
+
+	var re *stats.RegistrationError
+
+	if assert.True(t, errors.As(errs[2], &re)) {
+		assert.Equal(t, "fiz", re.Name)
+	}
+
+	assert.Panics(t, func() { stats.RootScope(c).Counter("fiz") })
+
+	fs, err := r.Gather()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(fs))
+}
@@ -0,0 +1,181 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/upfluence/log"
+)
+
+// PushMethod defines how the metrics are sent to the push gateway.
+type PushMethod int
+
+const (
+	// PushMethodPut replaces all the metrics of the grouping key (HTTP PUT).
+	PushMethodPut PushMethod = iota
+
+	// PushMethodPost only replaces the metrics with the same name in the
+	// grouping key (HTTP POST).
+	PushMethodPost
+)
+
+var defaultExporterOptions = exporterOptions{
+	interval:   15 * time.Second,
+	method:     PushMethodPut,
+	registerer: prometheus.DefaultRegisterer,
+	gatherer:   prometheus.DefaultGatherer,
+}
+
+type grouping struct {
+	name  string
+	value string
+}
+
+type exporterOptions struct {
+	interval time.Duration
+	method   PushMethod
+	grouping []grouping
+
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+
+	client push.HTTPDoer
+}
+
+// ExporterOption configures an Exporter with custom settings.
+type ExporterOption func(*exporterOptions)
+
+// WithInterval configures the delay between two pushes. Default is 15s.
+func WithInterval(d time.Duration) ExporterOption {
+	return func(opts *exporterOptions) { opts.interval = d }
+}
+
+// WithGrouping adds a grouping key label to the pushed metrics.
+func WithGrouping(name, value string) ExporterOption {
+	return func(opts *exporterOptions) {
+		opts.grouping = append(opts.grouping, grouping{name: name, value: value})
+	}
+}
+
+// WithPushMethod configures the HTTP method used to push the metrics.
+// Default is PushMethodPut.
+func WithPushMethod(m PushMethod) ExporterOption {
+	return func(opts *exporterOptions) { opts.method = m }
+}
+
+// WithRegistry configures the registry the metrics are registered into and
+// gathered from. Default is the prometheus default registry.
+func WithRegistry(r *prometheus.Registry) ExporterOption {
+	return func(opts *exporterOptions) {
+		opts.registerer = r
+		opts.gatherer = r
+	}
+}
+
+// WithHTTPClient configures the HTTP client used to reach the push gateway.
+func WithHTTPClient(c push.HTTPDoer) ExporterOption {
+	return func(opts *exporterOptions) { opts.client = c }
+}
+
+// Exporter is a collector periodically pushing its metrics to a prometheus
+// push gateway. A last push is performed when the exporter is closed.
+type Exporter struct {
+	*Collector
+
+	logger log.Logger
+	pusher *push.Pusher
+	method PushMethod
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewExporter creates a new exporter pushing to the push gateway located at
+// url under the given job name. The exporter starts pushing right away.
+func NewExporter(l log.Logger, url, job string, eOpts ...ExporterOption) *Exporter {
+	var opts = defaultExporterOptions
+
+	for _, opt := range eOpts {
+		opt(&opts)
+	}
+
+	p := push.New(url, job).Gatherer(opts.gatherer)
+
+	for _, g := range opts.grouping {
+		p = p.Grouping(g.name, g.value)
+	}
+
+	if opts.client != nil {
+		p = p.Client(opts.client)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e := &Exporter{
+		Collector: NewCollector(opts.registerer, WithGatherer(opts.gatherer)),
+		logger:    l,
+		pusher:    p,
+		method:    opts.method,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	go e.run(ctx, opts.interval)
+
+	return e
+}
+
+func (e *Exporter) run(ctx context.Context, d time.Duration) {
+	defer close(e.done)
+
+	t := time.NewTicker(d)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			e.push(ctx)
+		}
+	}
+}
+
+func (e *Exporter) push(ctx context.Context) error {
+	var err error
+
+	switch e.method {
+	case PushMethodPost:
+		err = e.pusher.AddContext(ctx)
+	default:
+		err = e.pusher.PushContext(ctx)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		e.logger.WithError(err).Error("failed to push the metrics to the push gateway")
+	}
+
+	return err
+}
+
+// Close stops the periodic pushes and performs a final push.
+func (e *Exporter) Close() error {
+	var err error
+
+	e.closeOnce.Do(func() {
+		e.cancel()
+		<-e.done
+
+		err = e.push(context.Background())
+
+		if cerr := e.Collector.Close(); err == nil {
+			err = cerr
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,149 @@
+package prometheus
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/upfluence/log/logtest"
+
+	"github.com/upfluence/stats"
+)
+
+type pushRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+type pushGateway struct {
+	mu   sync.Mutex
+	reqs []pushRequest
+}
+
+func (pg *pushGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	buf, _ := io.ReadAll(r.Body)
+
+	pg.mu.Lock()
+	pg.reqs = append(
+		pg.reqs,
+		pushRequest{method: r.Method, path: r.URL.Path, body: string(buf)},
+	)
+	pg.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (pg *pushGateway) requests() []pushRequest {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	return append([]pushRequest(nil), pg.reqs...)
+}
+
+func TestExporter(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		opts       []ExporterOption
+		wantMethod string
+		wantPath   string
+	}{
+		{
+			name:       "default",
+			wantMethod: http.MethodPut,
+			wantPath:   "/metrics/job/foo",
+		},
+		{
+			name: "post with grouping",
+			opts: []ExporterOption{
+				WithPushMethod(PushMethodPost),
+				WithGrouping("instance", "bar"),
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/metrics/job/foo/instance/bar",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var pg pushGateway
+
+			srv := httptest.NewServer(&pg)
+			defer srv.Close()
+
+			e := NewExporter(
+				logtest.WrapTestingLogger(t),
+				srv.URL,
+				"foo",
+				append(
+					tt.opts,
+					WithRegistry(prometheus.NewRegistry()),
+					WithInterval(time.Hour),
+				)...,
+			)
+
+			stats.RootScope(e).Counter("requests_total").Add(3)
+
+			assert.Nil(t, e.Close())
+			assert.Nil(t, e.Close())
+
+			reqs := pg.requests()
+
+			assert.Len(t, reqs, 1)
+			assert.Equal(t, tt.wantMethod, reqs[0].method)
+			assert.Equal(t, tt.wantPath, reqs[0].path)
+			assert.True(t, strings.Contains(reqs[0].body, "requests_total"))
+		})
+	}
+}
+
+func TestExporterPeriodicPush(t *testing.T) {
+	var pg pushGateway
+
+	srv := httptest.NewServer(&pg)
+	defer srv.Close()
+
+	e := NewExporter(
+		logtest.WrapTestingLogger(t),
+		srv.URL,
+		"foo",
+		WithRegistry(prometheus.NewRegistry()),
+		WithInterval(time.Millisecond),
+	)
+
+	stats.RootScope(e).Gauge("foo").Update(1)
+
+	assert.Eventually(
+		t,
+		func() bool { return len(pg.requests()) > 0 },
+		time.Second,
+		time.Millisecond,
+	)
+
+	assert.Nil(t, e.Close())
+}
+
+func TestExporterFailure(t *testing.T) {
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}),
+	)
+	defer srv.Close()
+
+	e := NewExporter(
+		logtest.WrapTestingLogger(t),
+		srv.URL,
+		"foo",
+		WithRegistry(prometheus.NewRegistry()),
+		WithInterval(time.Hour),
+	)
+
+	stats.RootScope(e).Gauge("foo").Update(1)
+
+	assert.NotNil(t, e.Close())
+}
@@ -0,0 +1,156 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/upfluence/stats"
+)
+
+type multiFloat64VectorGetter struct {
+	mode registrarMode
+
+	mu sync.RWMutex
+	gs []stats.Float64VectorGetter
+}
+
+func (mfvg *multiFloat64VectorGetter) appendGetter(n string, m registrarMode, g stats.Float64VectorGetter) {
+	mfvg.mu.Lock()
+	defer mfvg.mu.Unlock()
+
+	if len(mfvg.gs) > 0 {
+		if hashSlice(g.Labels()) != hashSlice(mfvg.gs[0].Labels()) {
+			panic(
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.LabelsConflict,
+					Has:  g.Labels(),
+					Want: mfvg.gs[0].Labels(),
+				},
+			)
+		}
+
+		if mfvg.mode != m {
+			panic(
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.TypeConflict,
+					Has:  m,
+					Want: mfvg.mode,
+				},
+			)
+		}
+	}
+
+	if len(mfvg.gs) == 0 {
+		mfvg.mode = m
+	}
+
+	mfvg.gs = append(mfvg.gs, g)
+}
+
+// removeGetter removes the getter, it returns true if no getter is left.
+func (mfvg *multiFloat64VectorGetter) removeGetter(g interface{}) bool {
+	mfvg.mu.Lock()
+	defer mfvg.mu.Unlock()
+
+	mfvg.gs, _ = removeGetter(mfvg.gs, g)
+
+	return len(mfvg.gs) == 0
+}
+
+func (mfvg *multiFloat64VectorGetter) getters() []stats.Float64VectorGetter {
+	mfvg.mu.RLock()
+	defer mfvg.mu.RUnlock()
+
+	return mfvg.gs
+}
+
+func (mfvg *multiFloat64VectorGetter) Labels() []string {
+	gs := mfvg.getters()
+
+	if len(gs) == 0 {
+		return nil
+	}
+
+	return gs[0].Labels()
+}
+
+func (mfvg *multiFloat64VectorGetter) Get() []*stats.Float64Value {
+	gs := mfvg.getters()
+
+	switch len(gs) {
+	case 0:
+		return nil
+	case 1:
+		return gs[0].Get()
+	}
+
+	var (
+		tags   = make(map[uint64]map[string]string)
+		values = make(map[uint64]float64)
+	)
+
+	for _, g := range gs {
+		for _, fv := range g.Get() {
+			key := hashTags(fv.Tags)
+
+			if _, ok := tags[key]; !ok {
+				tags[key] = fv.Tags
+				values[key] = fv.Value
+			} else if mfvg.mode == counter {
+				values[key] += fv.Value
+			}
+		}
+	}
+
+	res := make([]*stats.Float64Value, 0, len(tags))
+
+	for key, ts := range tags {
+		res = append(res, &stats.Float64Value{Tags: ts, Value: values[key]})
+	}
+
+	return res
+}
+
+type float64Wrapper struct {
+	g stats.Float64VectorGetter
+	n string
+
+	desc *prometheus.Desc
+
+	stapler func(*dto.Metric, float64)
+}
+
+type float64MetricImpl struct {
+	desc    *prometheus.Desc
+	v       *stats.Float64Value
+	stapler func(*dto.Metric, float64)
+}
+
+func (fm *float64MetricImpl) Desc() *prometheus.Desc { return fm.desc }
+func (fm *float64MetricImpl) Write(m *dto.Metric) error {
+	var ps []*dto.LabelPair
+
+	for k, v := range fm.v.Tags {
+		k, v := k, v
+		ps = append(ps, &dto.LabelPair{Name: &k, Value: &v})
+	}
+
+	fm.stapler(m, fm.v.Value)
+	m.Label = ps
+
+	return nil
+}
+
+func (fw *float64Wrapper) Describe(ch chan<- *prometheus.Desc) {
+	ch <- fw.desc
+}
+
+func (fw *float64Wrapper) Collect(ch chan<- prometheus.Metric) {
+	for _, v := range fw.g.Get() {
+		ch <- &float64MetricImpl{desc: fw.desc, v: v, stapler: fw.stapler}
+	}
+}
@@ -1,8 +1,8 @@
 package prometheus
 
 import (
-	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -12,30 +12,34 @@ import (
 )
 
 type multiHistogramVectorGetter struct {
+	mu sync.RWMutex
 	gs []stats.HistogramVectorGetter
 }
 
 func (mhvg *multiHistogramVectorGetter) appendGetter(n string, g stats.HistogramVectorGetter) {
+	mhvg.mu.Lock()
+	defer mhvg.mu.Unlock()
+
 	if len(mhvg.gs) > 0 {
 		if hashSlice(g.Labels()) != hashSlice(mhvg.gs[0].Labels()) {
 			panic(
-				fmt.Sprintf(
-					"%s: histogram cannot be registered because the labels are different, has: %v, want %v",
-					n,
-					g.Labels(),
-					mhvg.gs[0].Labels(),
-				),
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.LabelsConflict,
+					Has:  g.Labels(),
+					Want: mhvg.gs[0].Labels(),
+				},
 			)
 		}
 
 		if hashFloat64Slice(g.Cutoffs()) != hashFloat64Slice(mhvg.gs[0].Cutoffs()) {
 			panic(
-				fmt.Sprintf(
-					"%s: histogram cannot be registered because the cutoffs are different, has: %v, want %v",
-					n,
-					g.Cutoffs(),
-					mhvg.gs[0].Cutoffs(),
-				),
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.CutoffsConflict,
+					Has:  g.Cutoffs(),
+					Want: mhvg.gs[0].Cutoffs(),
+				},
 			)
 		}
 	}
@@ -43,28 +47,57 @@ func (mhvg *multiHistogramVectorGetter) appendGetter(n string, g stats.Histogram
 	mhvg.gs = append(mhvg.gs, g)
 }
 
+// removeGetter removes the getter, it returns true if no getter is left.
+func (mhvg *multiHistogramVectorGetter) removeGetter(g interface{}) bool {
+	mhvg.mu.Lock()
+	defer mhvg.mu.Unlock()
+
+	mhvg.gs, _ = removeGetter(mhvg.gs, g)
+
+	return len(mhvg.gs) == 0
+}
+
+func (mhvg *multiHistogramVectorGetter) getters() []stats.HistogramVectorGetter {
+	mhvg.mu.RLock()
+	defer mhvg.mu.RUnlock()
+
+	return mhvg.gs
+}
+
 func (mhvg *multiHistogramVectorGetter) Labels() []string {
-	if len(mhvg.gs) == 0 {
+	gs := mhvg.getters()
+
+	if len(gs) == 0 {
 		return nil
 	}
 
-	return mhvg.gs[0].Labels()
+	return gs[0].Labels()
 }
 
 func (mhvg *multiHistogramVectorGetter) Cutoffs() []float64 {
-	if len(mhvg.gs) == 0 {
+	gs := mhvg.getters()
+
+	if len(gs) == 0 {
 		return nil
 	}
 
-	return mhvg.gs[0].Cutoffs()
+	return gs[0].Cutoffs()
+}
+
+func (mhvg *multiHistogramVectorGetter) TracksExtrema() bool {
+	gs := mhvg.getters()
+
+	return len(gs) > 0 && stats.TracksExtrema(gs[0])
 }
 
 func (mhvg *multiHistogramVectorGetter) Get() []*stats.HistogramValue {
-	switch len(mhvg.gs) {
+	gs := mhvg.getters()
+
+	switch len(gs) {
 	case 0:
 		return nil
 	case 1:
-		return mhvg.gs[0].Get()
+		return gs[0].Get()
 	}
 
 	var (
@@ -72,9 +105,11 @@ func (mhvg *multiHistogramVectorGetter) Get() []*stats.HistogramValue {
 		counts  = make(map[uint64]int64)
 		sums    = make(map[uint64]float64)
 		buckets = make(map[uint64]map[float64]int64)
+		natives = make(map[uint64]*stats.NativeHistogramValue)
+		extrema = make(map[uint64]*stats.HistogramExtrema)
 	)
 
-	for _, g := range mhvg.gs {
+	for _, g := range gs {
 		for _, hv := range g.Get() {
 			key := hashTags(hv.Tags)
 
@@ -85,6 +120,8 @@ func (mhvg *multiHistogramVectorGetter) Get() []*stats.HistogramValue {
 
 			counts[key] += hv.Count
 			sums[key] += hv.Sum
+			natives[key] = mergeNativeHistograms(natives[key], hv.Native)
+			extrema[key] = mergeHistogramExtrema(extrema[key], hv.Extrema)
 
 			for _, b := range hv.Buckets {
 				buckets[key][b.UpperBound] += b.Count
@@ -100,6 +137,8 @@ func (mhvg *multiHistogramVectorGetter) Get() []*stats.HistogramValue {
 			Count:   counts[key],
 			Sum:     sums[key],
 			Buckets: make([]stats.Bucket, 0, len(buckets[key])),
+			Native:  natives[key],
+			Extrema: extrema[key],
 		}
 
 		for ub, count := range buckets[key] {
@@ -122,20 +161,96 @@ func (mhvg *multiHistogramVectorGetter) Get() []*stats.HistogramValue {
 	return res
 }
 
+func mergeNativeHistograms(x, y *stats.NativeHistogramValue) *stats.NativeHistogramValue {
+	if x == nil {
+		return y
+	}
+
+	if y == nil {
+		return x
+	}
+
+	var schema = min(x.Schema, y.Schema)
+
+	x = stats.DownscaleNativeHistogram(x, schema)
+	y = stats.DownscaleNativeHistogram(y, schema)
+
+	x.ZeroCount += y.ZeroCount
+	x.ZeroThreshold = max(x.ZeroThreshold, y.ZeroThreshold)
+
+	for k, v := range y.PositiveBuckets {
+		x.PositiveBuckets[k] += v
+	}
+
+	for k, v := range y.NegativeBuckets {
+		x.NegativeBuckets[k] += v
+	}
+
+	return x
+}
+
+func mergeHistogramExtrema(x, y *stats.HistogramExtrema) *stats.HistogramExtrema {
+	if x == nil {
+		return y
+	}
+
+	if y == nil {
+		return x
+	}
+
+	return &stats.HistogramExtrema{
+		Min: min(x.Min, y.Min),
+		Max: max(x.Max, y.Max),
+	}
+}
+
 type histogramWrapper struct {
 	g stats.HistogramVectorGetter
 	n string
 
 	desc *prometheus.Desc
+
+	// minDesc and maxDesc are only set for the histograms tracking their
+	// extrema, exposed as gauges next to the histogram.
+	minDesc *prometheus.Desc
+	maxDesc *prometheus.Desc
 }
 
 func (hw *histogramWrapper) Describe(ch chan<- *prometheus.Desc) {
 	ch <- hw.desc
+
+	if hw.minDesc != nil {
+		ch <- hw.minDesc
+		ch <- hw.maxDesc
+	}
 }
 
 func (hw *histogramWrapper) Collect(ch chan<- prometheus.Metric) {
 	for _, v := range hw.g.Get() {
 		ch <- &histogramMetric{desc: hw.desc, v: v}
+
+		if hw.minDesc == nil || v.Extrema == nil {
+			continue
+		}
+
+		vs := make([]string, len(hw.g.Labels()))
+
+		for i, l := range hw.g.Labels() {
+			vs[i] = v.Tags[l]
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			hw.minDesc,
+			prometheus.GaugeValue,
+			v.Extrema.Min,
+			vs...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			hw.maxDesc,
+			prometheus.GaugeValue,
+			v.Extrema.Max,
+			vs...,
+		)
 	}
 }
 
@@ -175,7 +290,68 @@ func (hm *histogramMetric) Write(m *dto.Metric) error {
 		)
 	}
 
+	if nv := hm.v.Native; nv != nil {
+		m.Histogram.Schema = proto.Int32(nv.Schema)
+		m.Histogram.ZeroThreshold = proto.Float64(nv.ZeroThreshold)
+		m.Histogram.ZeroCount = proto.Uint64(uint64(nv.ZeroCount))
+		m.Histogram.PositiveSpan, m.Histogram.PositiveDelta = nativeSpans(nv.PositiveBuckets)
+		m.Histogram.NegativeSpan, m.Histogram.NegativeDelta = nativeSpans(nv.NegativeBuckets)
+
+		if len(m.Histogram.PositiveSpan) == 0 && len(m.Histogram.NegativeSpan) == 0 {
+			// An empty span flags the histogram as native even without any
+			// observation.
+			m.Histogram.PositiveSpan = []*dto.BucketSpan{
+				{Offset: proto.Int32(0), Length: proto.Uint32(0)},
+			}
+		}
+	}
+
 	m.Label = ps
 
 	return nil
 }
+
+// nativeSpans encodes the sparse buckets into spans of consecutive buckets
+// and the count deltas between consecutive buckets.
+func nativeSpans(bs map[int]int64) ([]*dto.BucketSpan, []int64) {
+	var (
+		keys = make([]int, 0, len(bs))
+
+		spans  []*dto.BucketSpan
+		deltas []int64
+
+		prevKey   int
+		prevCount int64
+	)
+
+	for k := range bs {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+
+	for i, k := range keys {
+		switch {
+		case i == 0:
+			spans = append(
+				spans,
+				&dto.BucketSpan{Offset: proto.Int32(int32(k)), Length: proto.Uint32(0)},
+			)
+		case k > prevKey+1:
+			spans = append(
+				spans,
+				&dto.BucketSpan{
+					Offset: proto.Int32(int32(k - prevKey - 1)),
+					Length: proto.Uint32(0),
+				},
+			)
+		}
+
+		*spans[len(spans)-1].Length++
+
+		deltas = append(deltas, bs[k]-prevCount)
+		prevKey, prevCount = k, bs[k]
+	}
+
+	return spans, deltas
+}
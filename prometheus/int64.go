@@ -1,7 +1,7 @@
 package prometheus
 
 import (
-	"fmt"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -16,6 +16,14 @@ const (
 	gauge
 )
 
+func (m registrarMode) String() string {
+	if m == counter {
+		return "counter"
+	}
+
+	return "gauge"
+}
+
 var registrarModeOps = map[registrarMode]func(*dto.Metric, float64){
 	gauge:   func(m *dto.Metric, v float64) { m.Gauge = &dto.Gauge{Value: &v} },
 	counter: func(m *dto.Metric, v float64) { m.Counter = &dto.Counter{Value: &v} },
@@ -23,50 +31,81 @@ var registrarModeOps = map[registrarMode]func(*dto.Metric, float64){
 
 type multiInt64VectorGetter struct {
 	mode registrarMode
-	gs   []stats.Int64VectorGetter
+
+	mu sync.RWMutex
+	gs []stats.Int64VectorGetter
 }
 
 func (mivg *multiInt64VectorGetter) appendGetter(n string, m registrarMode, g stats.Int64VectorGetter) {
+	mivg.mu.Lock()
+	defer mivg.mu.Unlock()
+
 	if len(mivg.gs) > 0 {
 		if hashSlice(g.Labels()) != hashSlice(mivg.gs[0].Labels()) {
 			panic(
-				fmt.Sprintf(
-					"%s: int64 vector cannot be registered because the labels are different, has: %v, want %v",
-					n,
-					g.Labels(),
-					mivg.gs[0].Labels(),
-				),
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.LabelsConflict,
+					Has:  g.Labels(),
+					Want: mivg.gs[0].Labels(),
+				},
 			)
 		}
 
 		if mivg.mode != m {
 			panic(
-				fmt.Sprintf(
-					"%s: invalid int64 vector mode, passed a counter but is a gauge or vice versa",
-					n,
-				),
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.TypeConflict,
+					Has:  m,
+					Want: mivg.mode,
+				},
 			)
 		}
 	}
 
-	mivg.mode = m
+	if len(mivg.gs) == 0 {
+		mivg.mode = m
+	}
+
 	mivg.gs = append(mivg.gs, g)
 }
 
+// removeGetter removes the getter, it returns true if no getter is left.
+func (mivg *multiInt64VectorGetter) removeGetter(g interface{}) bool {
+	mivg.mu.Lock()
+	defer mivg.mu.Unlock()
+
+	mivg.gs, _ = removeGetter(mivg.gs, g)
+
+	return len(mivg.gs) == 0
+}
+
+func (mivg *multiInt64VectorGetter) getters() []stats.Int64VectorGetter {
+	mivg.mu.RLock()
+	defer mivg.mu.RUnlock()
+
+	return mivg.gs
+}
+
 func (mivg *multiInt64VectorGetter) Labels() []string {
-	if len(mivg.gs) == 0 {
+	gs := mivg.getters()
+
+	if len(gs) == 0 {
 		return nil
 	}
 
-	return mivg.gs[0].Labels()
+	return gs[0].Labels()
 }
 
 func (mivg *multiInt64VectorGetter) Get() []*stats.Int64Value {
-	switch len(mivg.gs) {
+	gs := mivg.getters()
+
+	switch len(gs) {
 	case 0:
 		return nil
 	case 1:
-		return mivg.gs[0].Get()
+		return gs[0].Get()
 	}
 
 	var (
@@ -74,7 +113,7 @@ func (mivg *multiInt64VectorGetter) Get() []*stats.Int64Value {
 		values = make(map[uint64]int64)
 	)
 
-	for _, g := range mivg.gs {
+	for _, g := range gs {
 		for _, iv := range g.Get() {
 			key := hashTags(iv.Tags)
 
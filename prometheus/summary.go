@@ -0,0 +1,181 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/upfluence/stats"
+)
+
+type multiSummaryVectorGetter struct {
+	mu sync.RWMutex
+	gs []stats.SummaryVectorGetter
+}
+
+func (msvg *multiSummaryVectorGetter) appendGetter(n string, g stats.SummaryVectorGetter) {
+	msvg.mu.Lock()
+	defer msvg.mu.Unlock()
+
+	if len(msvg.gs) > 0 {
+		if hashSlice(g.Labels()) != hashSlice(msvg.gs[0].Labels()) {
+			panic(
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.LabelsConflict,
+					Has:  g.Labels(),
+					Want: msvg.gs[0].Labels(),
+				},
+			)
+		}
+
+		if hashFloat64Slice(g.Objectives()) != hashFloat64Slice(msvg.gs[0].Objectives()) {
+			panic(
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.ObjectivesConflict,
+					Has:  g.Objectives(),
+					Want: msvg.gs[0].Objectives(),
+				},
+			)
+		}
+	}
+
+	msvg.gs = append(msvg.gs, g)
+}
+
+// removeGetter removes the getter, it returns true if no getter is left.
+func (msvg *multiSummaryVectorGetter) removeGetter(g interface{}) bool {
+	msvg.mu.Lock()
+	defer msvg.mu.Unlock()
+
+	msvg.gs, _ = removeGetter(msvg.gs, g)
+
+	return len(msvg.gs) == 0
+}
+
+func (msvg *multiSummaryVectorGetter) getters() []stats.SummaryVectorGetter {
+	msvg.mu.RLock()
+	defer msvg.mu.RUnlock()
+
+	return msvg.gs
+}
+
+func (msvg *multiSummaryVectorGetter) Labels() []string {
+	gs := msvg.getters()
+
+	if len(gs) == 0 {
+		return nil
+	}
+
+	return gs[0].Labels()
+}
+
+func (msvg *multiSummaryVectorGetter) Objectives() []float64 {
+	gs := msvg.getters()
+
+	if len(gs) == 0 {
+		return nil
+	}
+
+	return gs[0].Objectives()
+}
+
+// Get merges the values of the getters sharing the same tags, the quantiles
+// can not be aggregated so the ones of the first getter are reported.
+func (msvg *multiSummaryVectorGetter) Get() []*stats.SummaryValue {
+	gs := msvg.getters()
+
+	switch len(gs) {
+	case 0:
+		return nil
+	case 1:
+		return gs[0].Get()
+	}
+
+	var (
+		keys   []uint64
+		values = make(map[uint64]*stats.SummaryValue)
+	)
+
+	for _, g := range gs {
+		for _, sv := range g.Get() {
+			key := hashTags(sv.Tags)
+
+			if v, ok := values[key]; ok {
+				v.Count += sv.Count
+				v.Sum += sv.Sum
+
+				continue
+			}
+
+			v := *sv
+			values[key] = &v
+			keys = append(keys, key)
+		}
+	}
+
+	res := make([]*stats.SummaryValue, 0, len(keys))
+
+	for _, key := range keys {
+		res = append(res, values[key])
+	}
+
+	return res
+}
+
+type summaryWrapper struct {
+	g stats.SummaryVectorGetter
+	n string
+
+	desc *prometheus.Desc
+}
+
+func (sw *summaryWrapper) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sw.desc
+}
+
+func (sw *summaryWrapper) Collect(ch chan<- prometheus.Metric) {
+	for _, v := range sw.g.Get() {
+		ch <- &summaryMetric{desc: sw.desc, v: v}
+	}
+}
+
+type summaryMetric struct {
+	desc *prometheus.Desc
+	v    *stats.SummaryValue
+}
+
+func (sm *summaryMetric) Desc() *prometheus.Desc {
+	return sm.desc
+}
+
+func (sm *summaryMetric) Write(m *dto.Metric) error {
+	var ps []*dto.LabelPair
+
+	for k, v := range sm.v.Tags {
+		k, v := k, v
+		ps = append(ps, &dto.LabelPair{Name: &k, Value: &v})
+	}
+
+	m.Summary = &dto.Summary{
+		SampleCount: proto.Uint64(uint64(sm.v.Count)),
+		SampleSum:   proto.Float64(sm.v.Sum),
+	}
+
+	for _, q := range sm.v.Quantiles {
+		m.Summary.Quantile = append(
+			m.Summary.Quantile,
+			&dto.Quantile{
+				Quantile: proto.Float64(q.Quantile),
+				Value:    proto.Float64(q.Value),
+			},
+		)
+	}
+
+	m.Label = ps
+
+	return nil
+}
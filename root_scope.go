@@ -1,9 +1,11 @@
 package stats
 
 import (
-	"fmt"
+	"errors"
 	"sort"
 	"sync"
+
+	"github.com/upfluence/stats/internal/quantile"
 )
 
 type rootScope struct {
@@ -11,37 +13,123 @@ type rootScope struct {
 	mu sync.Mutex
 	lm labelMarshaler
 
-	counters   map[string]*atomicInt64Vector
-	gauges     map[string]*atomicInt64Vector
-	histograms map[string]*histogramVector
+	onError func(error)
+	naming  NamingPolicy
+
+	counters      map[string]*atomicInt64Vector
+	gauges        map[string]*atomicInt64Vector
+	floatCounters map[string]*atomicFloat64Vector
+	floatGauges   map[string]*atomicFloat64Vector
+	counterFuncs  map[string]*funcInt64Vector
+	gaugeFuncs    map[string]*funcInt64Vector
+	histograms    map[string]*histogramVector
+	summaries     map[string]*summaryVector
+
+	// derived holds the names the collectors derive from the registered
+	// metrics, such as the _min and _max of the histograms tracking their
+	// extrema, mapped to the type of the metric they are derived from.
+	derived map[string]string
+
+	overflows CounterVector
+}
+
+// RootScopeOption configures a root scope.
+type RootScopeOption func(*rootScope)
+
+// WithErrorHandler creates a RootScopeOption reporting the registration
+// conflicts to fn as *ConflictError instead of panicking, the conflicting
+// metric is replaced by a noop metric. The conflicts detected by the
+// collector are reported as well if it panics with a *ConflictError, or with
+// a *RegistrationError for the metrics it rejects otherwise.
+func WithErrorHandler(fn func(error)) RootScopeOption {
+	return func(rs *rootScope) { rs.onError = fn }
 }
 
 // RootScope creates a new root scope that registers metrics with the given collector.
 // This is the primary entry point for creating a metrics hierarchy.
-func RootScope(c Collector) Scope {
-	return scopeWrapper{
-		&rootScope{
-			c:          c,
-			lm:         newDefaultMarshaler(),
-			counters:   make(map[string]*atomicInt64Vector),
-			gauges:     make(map[string]*atomicInt64Vector),
-			histograms: make(map[string]*histogramVector),
-		},
+func RootScope(c Collector, opts ...RootScopeOption) Scope {
+	rs := rootScope{
+		c:             c,
+		lm:            newDefaultMarshaler(),
+		counters:      make(map[string]*atomicInt64Vector),
+		gauges:        make(map[string]*atomicInt64Vector),
+		floatCounters: make(map[string]*atomicFloat64Vector),
+		floatGauges:   make(map[string]*atomicFloat64Vector),
+		counterFuncs:  make(map[string]*funcInt64Vector),
+		gaugeFuncs:    make(map[string]*funcInt64Vector),
+		histograms:    make(map[string]*histogramVector),
+		summaries:     make(map[string]*summaryVector),
+		derived:       make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(&rs)
 	}
+
+	return scopeWrapper{&rs}
 }
 
-func (rs *rootScope) assertMetricUniqueness(n string) {
-	if _, ok := rs.counters[n]; ok {
-		panic(fmt.Sprintf("counter with the same name already registered: %q", n))
+// handleError panics with err unless the root scope has an error handler.
+func (rs *rootScope) handleError(err error) {
+	if rs.onError == nil {
+		panic(err)
 	}
 
-	if _, ok := rs.gauges[n]; ok {
-		panic(fmt.Sprintf("gauge with the same name already registered: %q", n))
+	rs.onError(err)
+}
+
+// register calls fn, which registers the metric with the collector. If the
+// root scope has an error handler, the *ConflictError and *RegistrationError
+// raised by the collector are recovered and returned.
+func (rs *rootScope) register(fn func()) (err error) {
+	if rs.onError != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				var (
+					ce *ConflictError
+					re *RegistrationError
+
+					e, ok = r.(error)
+				)
+
+				switch {
+				case ok && errors.As(e, &ce):
+					err = ce
+				case ok && errors.As(e, &re):
+					err = re
+				default:
+					panic(r)
+				}
+			}
+		}()
 	}
 
-	if _, ok := rs.histograms[n]; ok {
-		panic(fmt.Sprintf("hisogram with the same name already registered: %q", n))
+	fn()
+
+	return nil
+}
+
+func (rs *rootScope) assertMetricUniqueness(n, kind string) error {
+	for _, m := range []struct {
+		kind string
+		ok   bool
+	}{
+		{kind: "counter", ok: rs.counters[n] != nil},
+		{kind: "gauge", ok: rs.gauges[n] != nil},
+		{kind: "float counter", ok: rs.floatCounters[n] != nil},
+		{kind: "float gauge", ok: rs.floatGauges[n] != nil},
+		{kind: "counter func", ok: rs.counterFuncs[n] != nil},
+		{kind: "gauge func", ok: rs.gaugeFuncs[n] != nil},
+		{kind: "histogram", ok: rs.histograms[n] != nil},
+		{kind: "summary", ok: rs.summaries[n] != nil},
+		{kind: rs.derived[n], ok: rs.derived[n] != ""},
+	} {
+		if m.ok {
+			return &ConflictError{Name: n, Kind: TypeConflict, Has: kind, Want: m.kind}
+		}
 	}
+
+	return nil
 }
 
 type labelOrderer interface {
@@ -66,9 +154,9 @@ func (mlo mappingLabelOdrderer) order(in []string) []string {
 	return out
 }
 
-func buildLabelOrderer(base, target []string) labelOrderer {
+func buildLabelOrderer(n string, base, target []string) (labelOrderer, error) {
 	if len(base) != len(target) {
-		panic("Another metric is already created with different number of label")
+		return nil, &ConflictError{Name: n, Kind: LabelsConflict, Has: target, Want: base}
 	}
 
 	var (
@@ -81,7 +169,7 @@ func buildLabelOrderer(base, target []string) labelOrderer {
 
 	for i, bv := range baseCopy {
 		if targetCopy[i] != bv {
-			panic("Another metric is already created with different label")
+			return nil, &ConflictError{Name: n, Kind: LabelsConflict, Has: target, Want: base}
 		}
 	}
 
@@ -96,7 +184,7 @@ func buildLabelOrderer(base, target []string) labelOrderer {
 		}
 	}
 
-	return mappingLabelOdrderer{mapping: transferMap}
+	return mappingLabelOdrderer{mapping: transferMap}, nil
 }
 
 func (rs *rootScope) registerHistogram(n string, ls []string, opts ...HistogramOption) HistogramVector {
@@ -104,75 +192,466 @@ func (rs *rootScope) registerHistogram(n string, ls []string, opts ...HistogramO
 	defer rs.mu.Unlock()
 
 	if h, ok := rs.histograms[n]; ok {
-		// TODO: ensure the cutoffs are similare or panic
+		if err := assertSameCutoffs(n, h, opts); err != nil {
+			rs.handleError(err)
+			return NoopHistogramVector
+		}
+
+		lo, err := buildLabelOrderer(n, h.labels, ls)
 
-		return reorderHistogramVector{
-			hv:           h,
-			labelOrderer: buildLabelOrderer(h.labels, ls),
+		if err != nil {
+			rs.handleError(err)
+			return NoopHistogramVector
 		}
+
+		return reorderHistogramVector{hv: h, labelOrderer: lo}
+	}
+
+	if err := rs.assertMetricUniqueness(n, "histogram"); err != nil {
+		rs.handleError(err)
+		return NoopHistogramVector
 	}
 
-	rs.assertMetricUniqueness(n)
+	var (
+		v        = newHistogramVector(ls, rs.lm, opts...)
+		suffixes []string
+	)
 
-	v := &histogramVector{
-		cutoffs:   defaultCutoffs,
-		labels:    ls,
-		hs:        map[uint64]*histogram{},
-		marshaler: rs.lm,
+	if v.extrema != nil {
+		suffixes = append(suffixes, "_min", "_max")
 	}
 
-	for _, opt := range opts {
-		opt(v)
+	if v.observations.policy == CountInvalidObservations {
+		suffixes = append(suffixes, InvalidObservationsSuffix)
+	}
+
+	if err := rs.assertDerivedNames(n, "histogram", suffixes...); err != nil {
+		rs.handleError(err)
+		return NoopHistogramVector
+	}
+
+	v.onOverflow = rs.overflowFunc(n, v.maxSeries)
+
+	if err := rs.register(func() { rs.c.RegisterHistogram(n, v) }); err != nil {
+		rs.handleError(err)
+		return NoopHistogramVector
+	}
+
+	if v.observations.policy == CountInvalidObservations {
+		v.onInvalid = rs.invalidFunc(n, ls)
 	}
 
+	rs.reserveDerivedNames(n, "histogram", suffixes...)
 	rs.histograms[n] = v
-	rs.c.RegisterHistogram(n, v)
 
 	return v
 }
 
-func (rs *rootScope) registerGauge(n string, ls []string) GaugeVector {
+// assertSameCutoffs returns an error if the options do not lead to the same
+// cutoffs as the ones of the already registered histogram vector.
+func assertSameCutoffs(n string, hv *histogramVector, opts []HistogramOption) error {
+	cutoffs := newHistogramVector(nil, nil, opts...).cutoffs
+
+	if !equalFloat64Slices(hv.cutoffs, cutoffs) {
+		return &ConflictError{Name: n, Kind: CutoffsConflict, Has: cutoffs, Want: hv.cutoffs}
+	}
+
+	return nil
+}
+
+// assertSameObjectives returns an error if the options do not lead to the
+// same objectives as the ones of the already registered summary vector.
+func assertSameObjectives(n string, sv *summaryVector, opts []SummaryOption) error {
+	targets := newSummaryVector(nil, nil, opts...).targets
+
+	if !equalTargets(sv.targets, targets) {
+		return &ConflictError{
+			Name: n,
+			Kind: ObjectivesConflict,
+			Has:  targetsObjectives(targets),
+			Want: targetsObjectives(sv.targets),
+		}
+	}
+
+	return nil
+}
+
+func equalTargets(x, y []quantile.Target) bool {
+	if len(x) != len(y) {
+		return false
+	}
+
+	for i, t := range x {
+		if t != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func targetsObjectives(ts []quantile.Target) map[float64]float64 {
+	res := make(map[float64]float64, len(ts))
+
+	for _, t := range ts {
+		res[t.Quantile] = t.Epsilon
+	}
+
+	return res
+}
+
+func equalFloat64Slices(x, y []float64) bool {
+	if len(x) != len(y) {
+		return false
+	}
+
+	for i, v := range x {
+		if v != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (rs *rootScope) registerSummary(n string, ls []string, opts ...SummaryOption) SummaryVector {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if s, ok := rs.summaries[n]; ok {
+		if err := assertSameObjectives(n, s, opts); err != nil {
+			rs.handleError(err)
+			return NoopSummaryVector
+		}
+
+		lo, err := buildLabelOrderer(n, s.labels, ls)
+
+		if err != nil {
+			rs.handleError(err)
+			return NoopSummaryVector
+		}
+
+		return reorderSummaryVector{sv: s, labelOrderer: lo}
+	}
+
+	if err := rs.assertMetricUniqueness(n, "summary"); err != nil {
+		rs.handleError(err)
+		return NoopSummaryVector
+	}
+
+	if err := rs.assertDerivedNames(n, "summary", "_count", "_sum"); err != nil {
+		rs.handleError(err)
+		return NoopSummaryVector
+	}
+
+	v := newSummaryVector(ls, rs.lm, opts...)
+	v.onOverflow = rs.overflowFunc(n, v.maxSeries)
+
+	if err := rs.register(func() { RegisterSummary(rs.c, n, v) }); err != nil {
+		rs.handleError(err)
+		return NoopSummaryVector
+	}
+
+	rs.reserveDerivedNames(n, "summary", "_count", "_sum")
+	rs.summaries[n] = v
+
+	return v
+}
+
+func (rs *rootScope) registerGauge(n string, ls []string, opts ...MetricOption) GaugeVector {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
 	if c, ok := rs.gauges[n]; ok {
-		return reorderGaugeVector{
-			gv:           gaugeVector{c},
-			labelOrderer: buildLabelOrderer(c.labels, ls),
+		lo, err := buildLabelOrderer(n, c.labels, ls)
+
+		if err != nil {
+			rs.handleError(err)
+			return NoopGaugeVector
 		}
+
+		return reorderGaugeVector{gv: gaugeVector{c}, labelOrderer: lo}
+	}
+
+	if err := rs.assertMetricUniqueness(n, "gauge"); err != nil {
+		rs.handleError(err)
+		return NoopGaugeVector
 	}
 
-	rs.assertMetricUniqueness(n)
+	v := newAtomicInt64Vector(ls, rs.lm, buildMetricOptions(opts))
+	v.onOverflow = rs.overflowFunc(n, v.maxSeries)
 
-	v := newAtomicInt64Vector(ls, rs.lm)
+	if err := rs.register(func() { rs.c.RegisterGauge(n, v) }); err != nil {
+		rs.handleError(err)
+		return NoopGaugeVector
+	}
 
 	rs.gauges[n] = v
-	rs.c.RegisterGauge(n, v)
 
 	return gaugeVector{v}
 }
 
-func (rs *rootScope) registerCounter(n string, ls []string) CounterVector {
+func (rs *rootScope) registerCounter(n string, ls []string, opts ...MetricOption) CounterVector {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
+	return rs.registerCounterLocked(n, ls, opts...)
+}
+
+func (rs *rootScope) registerCounterLocked(n string, ls []string, opts ...MetricOption) CounterVector {
+	if k, ok := rs.derived[n]; ok {
+		rs.handleError(&ConflictError{Name: n, Kind: TypeConflict, Has: "counter", Want: k})
+		return NoopCounterVector
+	}
+
 	if c, ok := rs.counters[n]; ok {
-		return reorderCounterVector{
-			cv:           counterVector{c},
-			labelOrderer: buildLabelOrderer(c.labels, ls),
+		lo, err := buildLabelOrderer(n, c.labels, ls)
+
+		if err != nil {
+			rs.handleError(err)
+			return NoopCounterVector
 		}
+
+		return reorderCounterVector{cv: counterVector{c}, labelOrderer: lo}
 	}
 
-	rs.assertMetricUniqueness(n)
+	if err := rs.assertMetricUniqueness(n, "counter"); err != nil {
+		rs.handleError(err)
+		return NoopCounterVector
+	}
+
+	v := newAtomicInt64Vector(ls, rs.lm, buildMetricOptions(opts))
+	v.onOverflow = rs.overflowFunc(n, v.maxSeries)
+
+	if v.striped {
+		v.newFunc = func(map[string]string) interface{} { return newStripedInt64() }
+	}
 
-	v := newAtomicInt64Vector(ls, rs.lm)
+	if err := rs.register(func() { rs.c.RegisterCounter(n, v) }); err != nil {
+		rs.handleError(err)
+		return NoopCounterVector
+	}
 
 	rs.counters[n] = v
-	rs.c.RegisterCounter(n, v)
 
 	return counterVector{v}
 }
 
+func (rs *rootScope) registerFloatGauge(n string, ls []string, opts ...MetricOption) FloatGaugeVector {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if g, ok := rs.floatGauges[n]; ok {
+		lo, err := buildLabelOrderer(n, g.labels, ls)
+
+		if err != nil {
+			rs.handleError(err)
+			return NoopFloatGaugeVector
+		}
+
+		return reorderFloatGaugeVector{gv: floatGaugeVector{g}, labelOrderer: lo}
+	}
+
+	if err := rs.assertMetricUniqueness(n, "float gauge"); err != nil {
+		rs.handleError(err)
+		return NoopFloatGaugeVector
+	}
+
+	v := newAtomicFloat64Vector(ls, rs.lm, buildMetricOptions(opts))
+	v.onOverflow = rs.overflowFunc(n, v.maxSeries)
+
+	if err := rs.register(func() { RegisterFloatGauge(rs.c, n, v) }); err != nil {
+		rs.handleError(err)
+		return NoopFloatGaugeVector
+	}
+
+	rs.floatGauges[n] = v
+
+	return floatGaugeVector{v}
+}
+
+func (rs *rootScope) registerFloatCounter(n string, ls []string, opts ...MetricOption) FloatCounterVector {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if c, ok := rs.floatCounters[n]; ok {
+		lo, err := buildLabelOrderer(n, c.labels, ls)
+
+		if err != nil {
+			rs.handleError(err)
+			return NoopFloatCounterVector
+		}
+
+		return reorderFloatCounterVector{cv: floatCounterVector{c}, labelOrderer: lo}
+	}
+
+	if err := rs.assertMetricUniqueness(n, "float counter"); err != nil {
+		rs.handleError(err)
+		return NoopFloatCounterVector
+	}
+
+	v := newAtomicFloat64Vector(ls, rs.lm, buildMetricOptions(opts))
+	v.onOverflow = rs.overflowFunc(n, v.maxSeries)
+
+	if err := rs.register(func() { RegisterFloatCounter(rs.c, n, v) }); err != nil {
+		rs.handleError(err)
+		return NoopFloatCounterVector
+	}
+
+	rs.floatCounters[n] = v
+
+	return floatCounterVector{v}
+}
+
+func (rs *rootScope) registerGaugeFunc(n string, ls, vs []string, fn func() []LabeledInt64, opts ...MetricOption) {
+	rs.registerInt64Func(rs.gaugeFuncs, rs.c.RegisterGauge, "gauge func", n, ls, vs, fn, opts)
+}
+
+func (rs *rootScope) registerCounterFunc(n string, ls, vs []string, fn func() []LabeledInt64, opts ...MetricOption) {
+	rs.registerInt64Func(rs.counterFuncs, rs.c.RegisterCounter, "counter func", n, ls, vs, fn, opts)
+}
+
+func (rs *rootScope) registerInt64Func(
+	fvs map[string]*funcInt64Vector,
+	register func(string, Int64VectorGetter),
+	kind string,
+	n string,
+	ls, vs []string,
+	fn func() []LabeledInt64,
+	opts []MetricOption,
+) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if v, ok := fvs[n]; ok {
+		lo, err := buildLabelOrderer(n, v.labels, ls)
+
+		if err != nil {
+			rs.handleError(err)
+			return
+		}
+
+		v.appendSource(int64FuncSource{vs: vs, fn: fn, labelOrderer: lo})
+
+		return
+	}
+
+	if err := rs.assertMetricUniqueness(n, kind); err != nil {
+		rs.handleError(err)
+		return
+	}
+
+	v := &funcInt64Vector{
+		name:     n,
+		labels:   ls,
+		metadata: buildMetricOptions(opts).metadata,
+		onError:  rs.onError,
+		sources:  []int64FuncSource{{vs: vs, fn: fn}},
+	}
+
+	if err := rs.register(func() { register(n, v) }); err != nil {
+		rs.handleError(err)
+		return
+	}
+
+	fvs[n] = v
+}
+
+// overflowFunc returns the function called when the vector n overflows, the
+// overflow counter of the root scope is registered along the first vector
+// with a maximum number of series. rs.mu must be held.
+func (rs *rootScope) overflowFunc(n string, maxSeries int64) func() {
+	if maxSeries <= 0 {
+		return nil
+	}
+
+	if rs.overflows == nil {
+		rs.overflows = rs.registerDerivedCounter(
+			CardinalityOverflowMetric,
+			[]string{"metric"},
+			WithHelp("Number of series creations rejected by the maximum number of series"),
+		)
+	}
+
+	return rs.overflows.Bind(n).Inc
+}
+
+// invalidFunc registers the counter of the invalid observations of the
+// histogram vector n and returns the function incrementing it. rs.mu must be
+// held.
+func (rs *rootScope) invalidFunc(n string, ls []string) func([]string, int64) {
+	cv := rs.registerDerivedCounter(
+		n+InvalidObservationsSuffix,
+		ls,
+		WithHelp("Number of invalid observations dropped by "+n),
+	)
+
+	return func(vs []string, c int64) { cv.WithLabels(vs...).Add(c) }
+}
+
+// registerDerivedCounter registers the counter n derived by the root scope
+// from a metric, n goes through the naming policy as the other metrics.
+// rs.mu must be held.
+func (rs *rootScope) registerDerivedCounter(n string, ls []string, opts ...MetricOption) CounterVector {
+	n, ls, err := rs.applyNamingPolicy("", n, ls)
+
+	if err != nil {
+		rs.handleError(err)
+		return NoopCounterVector
+	}
+
+	return rs.registerCounterLocked(n, ls, opts...)
+}
+
+// Detach unregisters the metrics of the root scope of s from its collector,
+// if the collector implements Unregisterer. The metrics keep working but are
+// no longer exposed, registering them again through the root scope creates
+// new metrics.
+func Detach(s Scope) {
+	if rs := s.rootScope(); rs != nil {
+		rs.detach()
+	}
+}
+
+func (rs *rootScope) detach() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, vs := range []map[string]*atomicInt64Vector{rs.counters, rs.gauges} {
+		for n, v := range vs {
+			Unregister(rs.c, n, v)
+			delete(vs, n)
+		}
+	}
+
+	for _, vs := range []map[string]*atomicFloat64Vector{rs.floatCounters, rs.floatGauges} {
+		for n, v := range vs {
+			Unregister(rs.c, n, v)
+			delete(vs, n)
+		}
+	}
+
+	for _, vs := range []map[string]*funcInt64Vector{rs.counterFuncs, rs.gaugeFuncs} {
+		for n, v := range vs {
+			Unregister(rs.c, n, v)
+			delete(vs, n)
+		}
+	}
+
+	for n, v := range rs.histograms {
+		Unregister(rs.c, n, v)
+		delete(rs.histograms, n)
+	}
+
+	for n, v := range rs.summaries {
+		Unregister(rs.c, n, v)
+		delete(rs.summaries, n)
+	}
+
+	clear(rs.derived)
+	rs.overflows = nil
+}
+
 func (*rootScope) namespace() string        { return "" }
 func (*rootScope) tags() map[string]string  { return nil }
 func (rs *rootScope) rootScope() *rootScope { return rs }
@@ -12,7 +12,14 @@ type limitedScope interface {
 }
 
 // HistogramOption configures a histogram with custom settings.
-type HistogramOption func(*histogramVector)
+// MetricOption values are valid histogram options.
+type HistogramOption interface {
+	applyHistogram(*histogramVector)
+}
+
+type histogramOptionFunc func(*histogramVector)
+
+func (fn histogramOptionFunc) applyHistogram(hv *histogramVector) { fn(hv) }
 
 // Scope is the primary interface for creating and organizing metrics.
 // Scopes support hierarchical organization through namespaces and tag inheritance.
@@ -21,16 +28,46 @@ type Scope interface {
 	limitedScope
 
 	// Counter creates or retrieves a counter metric with the given name.
-	Counter(string) Counter
+	Counter(string, ...MetricOption) Counter
 
 	// CounterVector creates or retrieves a counter vector with the given name and labels.
-	CounterVector(string, []string) CounterVector
+	CounterVector(string, []string, ...MetricOption) CounterVector
 
 	// Gauge creates or retrieves a gauge metric with the given name.
-	Gauge(string) Gauge
+	Gauge(string, ...MetricOption) Gauge
 
 	// GaugeVector creates or retrieves a gauge vector with the given name and labels.
-	GaugeVector(string, []string) GaugeVector
+	GaugeVector(string, []string, ...MetricOption) GaugeVector
+
+	// FloatCounter creates or retrieves a float counter metric with the given name.
+	FloatCounter(string, ...MetricOption) FloatCounter
+
+	// FloatCounterVector creates or retrieves a float counter vector with the given name and labels.
+	FloatCounterVector(string, []string, ...MetricOption) FloatCounterVector
+
+	// FloatGauge creates or retrieves a float gauge metric with the given name.
+	FloatGauge(string, ...MetricOption) FloatGauge
+
+	// FloatGaugeVector creates or retrieves a float gauge vector with the given name and labels.
+	FloatGaugeVector(string, []string, ...MetricOption) FloatGaugeVector
+
+	// CounterFunc registers a counter whose value is computed by calling the
+	// given function at collection time.
+	CounterFunc(string, func() int64, ...MetricOption)
+
+	// CounterVectorFunc registers a counter vector with the given name and
+	// labels whose values are computed by calling the given function at
+	// collection time.
+	CounterVectorFunc(string, []string, func() []LabeledInt64, ...MetricOption)
+
+	// GaugeFunc registers a gauge whose value is computed by calling the
+	// given function at collection time.
+	GaugeFunc(string, func() int64, ...MetricOption)
+
+	// GaugeVectorFunc registers a gauge vector with the given name and labels
+	// whose values are computed by calling the given function at collection
+	// time.
+	GaugeVectorFunc(string, []string, func() []LabeledInt64, ...MetricOption)
 
 	// Histogram creates or retrieves a histogram metric with the given name and optional configuration.
 	Histogram(string, ...HistogramOption) Histogram
@@ -38,6 +75,12 @@ type Scope interface {
 	// HistogramVector creates or retrieves a histogram vector with the given name, labels, and optional configuration.
 	HistogramVector(string, []string, ...HistogramOption) HistogramVector
 
+	// Summary creates or retrieves a summary metric with the given name and optional configuration.
+	Summary(string, ...SummaryOption) Summary
+
+	// SummaryVector creates or retrieves a summary vector with the given name, labels, and optional configuration.
+	SummaryVector(string, []string, ...SummaryOption) SummaryVector
+
 	// Scope creates a child scope with the given namespace and tags.
 	// The namespace is appended to the parent's namespace with underscore separation.
 	// Tags are merged with the parent's tags, with child tags overriding parent values.
@@ -66,79 +109,165 @@ func (sw scopeWrapper) buildLabelValues() ([]string, []string) {
 	return labels, values
 }
 
-func (sw scopeWrapper) Histogram(name string, opts ...HistogramOption) Histogram {
+// metric applies the naming policy of the root scope to the name and the
+// labels of the metric, it returns the name of the metric, its labels and the
+// label values of the scope. It returns false if the naming policy rejected
+// a name, the error is reported to the root scope.
+func (sw scopeWrapper) metric(name string, labels []string) (string, []string, []string, bool) {
 	var (
-		ls, vs = sw.buildLabelValues()
+		rs      = sw.rootScope()
+		ns      = sw.namespace()
+		sls, vs = sw.buildLabelValues()
 
-		hv = sw.rootScope().registerHistogram(
-			joinStrings(sw.namespace(), name),
-			ls,
-			opts...,
-		)
+		n, ls, err = rs.applyNamingPolicy(ns, joinStrings(ns, name), append(sls, labels...))
 	)
 
-	return hv.WithLabels(vs...)
+	if err != nil {
+		rs.handleError(err)
+		return "", nil, nil, false
+	}
+
+	return n, ls, vs, true
+}
+
+func (sw scopeWrapper) Histogram(name string, opts ...HistogramOption) Histogram {
+	n, ls, vs, ok := sw.metric(name, nil)
+
+	if !ok {
+		return NoopHistogram
+	}
 
+	return sw.rootScope().registerHistogram(n, ls, opts...).WithLabels(vs...)
 }
 
 func (sw scopeWrapper) HistogramVector(name string, labels []string, opts ...HistogramOption) HistogramVector {
-	var (
-		sls, vs = sw.buildLabelValues()
+	n, ls, vs, ok := sw.metric(name, labels)
 
-		hv = sw.rootScope().registerHistogram(
-			joinStrings(sw.namespace(), name),
-			append(sls, labels...),
-			opts...,
-		)
-	)
+	if !ok {
+		return NoopHistogramVector
+	}
 
-	return partialHistogramVector{hv: hv, vs: vs}
+	return partialHistogramVector{hv: sw.rootScope().registerHistogram(n, ls, opts...), vs: vs}
 }
 
-func (sw scopeWrapper) Gauge(name string) Gauge {
-	var (
-		ls, vs = sw.buildLabelValues()
+func (sw scopeWrapper) Summary(name string, opts ...SummaryOption) Summary {
+	n, ls, vs, ok := sw.metric(name, nil)
 
-		gv = sw.rootScope().registerGauge(joinStrings(sw.namespace(), name), ls)
-	)
+	if !ok {
+		return NoopSummary
+	}
 
-	return gv.WithLabels(vs...)
+	return sw.rootScope().registerSummary(n, ls, opts...).WithLabels(vs...)
 }
 
-func (sw scopeWrapper) GaugeVector(name string, labels []string) GaugeVector {
-	var (
-		sls, vs = sw.buildLabelValues()
+func (sw scopeWrapper) SummaryVector(name string, labels []string, opts ...SummaryOption) SummaryVector {
+	n, ls, vs, ok := sw.metric(name, labels)
 
-		gv = sw.rootScope().registerGauge(
-			joinStrings(sw.namespace(), name),
-			append(sls, labels...),
-		)
-	)
+	if !ok {
+		return NoopSummaryVector
+	}
 
-	return partialGaugeVector{gv: gv, vs: vs}
+	return partialSummaryVector{sv: sw.rootScope().registerSummary(n, ls, opts...), vs: vs}
 }
 
-func (sw scopeWrapper) Counter(name string) Counter {
-	var (
-		ls, vs = sw.buildLabelValues()
+func (sw scopeWrapper) Gauge(name string, opts ...MetricOption) Gauge {
+	n, ls, vs, ok := sw.metric(name, nil)
 
-		cv = sw.rootScope().registerCounter(joinStrings(sw.namespace(), name), ls)
-	)
+	if !ok {
+		return NoopGauge
+	}
 
-	return cv.WithLabels(vs...)
+	return sw.rootScope().registerGauge(n, ls, opts...).WithLabels(vs...)
 }
 
-func (sw scopeWrapper) CounterVector(name string, labels []string) CounterVector {
-	var (
-		pls, vs = sw.buildLabelValues()
+func (sw scopeWrapper) GaugeVector(name string, labels []string, opts ...MetricOption) GaugeVector {
+	n, ls, vs, ok := sw.metric(name, labels)
 
-		cv = sw.rootScope().registerCounter(
-			joinStrings(sw.namespace(), name),
-			append(pls, labels...),
-		)
-	)
+	if !ok {
+		return NoopGaugeVector
+	}
+
+	return partialGaugeVector{gv: sw.rootScope().registerGauge(n, ls, opts...), vs: vs}
+}
+
+func (sw scopeWrapper) Counter(name string, opts ...MetricOption) Counter {
+	n, ls, vs, ok := sw.metric(name, nil)
+
+	if !ok {
+		return NoopCounter
+	}
+
+	return sw.rootScope().registerCounter(n, ls, opts...).WithLabels(vs...)
+}
+
+func (sw scopeWrapper) CounterVector(name string, labels []string, opts ...MetricOption) CounterVector {
+	n, ls, vs, ok := sw.metric(name, labels)
+
+	if !ok {
+		return NoopCounterVector
+	}
+
+	return partialCounterVector{cv: sw.rootScope().registerCounter(n, ls, opts...), vs: vs}
+}
+
+func (sw scopeWrapper) FloatGauge(name string, opts ...MetricOption) FloatGauge {
+	n, ls, vs, ok := sw.metric(name, nil)
+
+	if !ok {
+		return NoopFloatGauge
+	}
 
-	return partialCounterVector{cv: cv, vs: vs}
+	return sw.rootScope().registerFloatGauge(n, ls, opts...).WithLabels(vs...)
+}
+
+func (sw scopeWrapper) FloatGaugeVector(name string, labels []string, opts ...MetricOption) FloatGaugeVector {
+	n, ls, vs, ok := sw.metric(name, labels)
+
+	if !ok {
+		return NoopFloatGaugeVector
+	}
+
+	return partialFloatGaugeVector{gv: sw.rootScope().registerFloatGauge(n, ls, opts...), vs: vs}
+}
+
+func (sw scopeWrapper) FloatCounter(name string, opts ...MetricOption) FloatCounter {
+	n, ls, vs, ok := sw.metric(name, nil)
+
+	if !ok {
+		return NoopFloatCounter
+	}
+
+	return sw.rootScope().registerFloatCounter(n, ls, opts...).WithLabels(vs...)
+}
+
+func (sw scopeWrapper) FloatCounterVector(name string, labels []string, opts ...MetricOption) FloatCounterVector {
+	n, ls, vs, ok := sw.metric(name, labels)
+
+	if !ok {
+		return NoopFloatCounterVector
+	}
+
+	return partialFloatCounterVector{cv: sw.rootScope().registerFloatCounter(n, ls, opts...), vs: vs}
+}
+
+func (sw scopeWrapper) GaugeFunc(name string, fn func() int64, opts ...MetricOption) {
+	sw.GaugeVectorFunc(name, nil, scalarInt64Func(fn), opts...)
+}
+
+func (sw scopeWrapper) GaugeVectorFunc(name string, labels []string, fn func() []LabeledInt64, opts ...MetricOption) {
+	if n, ls, vs, ok := sw.metric(name, labels); ok {
+		sw.rootScope().registerGaugeFunc(n, ls, vs, fn, opts...)
+	}
+}
+
+func (sw scopeWrapper) CounterFunc(name string, fn func() int64, opts ...MetricOption) {
+	sw.CounterVectorFunc(name, nil, scalarInt64Func(fn), opts...)
+}
+
+func (sw scopeWrapper) CounterVectorFunc(name string, labels []string, fn func() []LabeledInt64, opts ...MetricOption) {
+	if n, ls, vs, ok := sw.metric(name, labels); ok {
+		sw.rootScope().registerCounterFunc(n, ls, vs, fn, opts...)
+	}
 }
 
 func (sw scopeWrapper) Scope(ns string, tags map[string]string) Scope {
@@ -214,16 +343,31 @@ func (noopScope) namespace() string       { return "" }
 func (noopScope) tags() map[string]string { return nil }
 func (noopScope) rootScope() *rootScope   { return nil }
 
-func (noopScope) Counter(string) Counter { return NoopCounter }
-func (noopScope) CounterVector(string, []string) CounterVector {
+func (noopScope) Counter(string, ...MetricOption) Counter { return NoopCounter }
+func (noopScope) CounterVector(string, []string, ...MetricOption) CounterVector {
 	return NoopCounterVector
 }
 
-func (noopScope) Gauge(string) Gauge { return NoopGauge }
-func (noopScope) GaugeVector(string, []string) GaugeVector {
+func (noopScope) Gauge(string, ...MetricOption) Gauge { return NoopGauge }
+func (noopScope) GaugeVector(string, []string, ...MetricOption) GaugeVector {
 	return NoopGaugeVector
 }
 
+func (noopScope) FloatCounter(string, ...MetricOption) FloatCounter { return NoopFloatCounter }
+func (noopScope) FloatCounterVector(string, []string, ...MetricOption) FloatCounterVector {
+	return NoopFloatCounterVector
+}
+
+func (noopScope) FloatGauge(string, ...MetricOption) FloatGauge { return NoopFloatGauge }
+func (noopScope) FloatGaugeVector(string, []string, ...MetricOption) FloatGaugeVector {
+	return NoopFloatGaugeVector
+}
+
+func (noopScope) CounterFunc(string, func() int64, ...MetricOption)                          {}
+func (noopScope) CounterVectorFunc(string, []string, func() []LabeledInt64, ...MetricOption) {}
+func (noopScope) GaugeFunc(string, func() int64, ...MetricOption)                            {}
+func (noopScope) GaugeVectorFunc(string, []string, func() []LabeledInt64, ...MetricOption)   {}
+
 func (noopScope) Histogram(string, ...HistogramOption) Histogram {
 	return NoopHistogram
 }
@@ -232,5 +376,10 @@ func (noopScope) HistogramVector(string, []string, ...HistogramOption) Histogram
 	return NoopHistogramVector
 }
 
+func (noopScope) Summary(string, ...SummaryOption) Summary { return NoopSummary }
+func (noopScope) SummaryVector(string, []string, ...SummaryOption) SummaryVector {
+	return NoopSummaryVector
+}
+
 func (noopScope) Scope(string, map[string]string) Scope { return noopScope{} }
 func (noopScope) RootScope() Scope                      { return noopScope{} }
@@ -9,17 +9,23 @@ import (
 // StaticCollector is a collector implementation useful for testing and debugging.
 // It stores all registered metrics and provides a snapshot interface to inspect values.
 type StaticCollector struct {
-	counters   map[string]Int64VectorGetter
-	gauges     map[string]Int64VectorGetter
-	histograms map[string]HistogramVectorGetter
+	counters      map[string]Int64VectorGetter
+	gauges        map[string]Int64VectorGetter
+	floatCounters map[string]Float64VectorGetter
+	floatGauges   map[string]Float64VectorGetter
+	histograms    map[string]HistogramVectorGetter
+	summaries     map[string]SummaryVectorGetter
 }
 
 // NewStaticCollector creates a new static collector for testing.
 func NewStaticCollector() *StaticCollector {
 	return &StaticCollector{
-		counters:   make(map[string]Int64VectorGetter),
-		gauges:     make(map[string]Int64VectorGetter),
-		histograms: make(map[string]HistogramVectorGetter),
+		counters:      make(map[string]Int64VectorGetter),
+		gauges:        make(map[string]Int64VectorGetter),
+		floatCounters: make(map[string]Float64VectorGetter),
+		floatGauges:   make(map[string]Float64VectorGetter),
+		histograms:    make(map[string]HistogramVectorGetter),
+		summaries:     make(map[string]SummaryVectorGetter),
 	}
 }
 
@@ -33,27 +39,73 @@ func (c *StaticCollector) RegisterGauge(n string, g Int64VectorGetter) {
 	c.gauges[n] = g
 }
 
+func (c *StaticCollector) RegisterFloatCounter(n string, g Float64VectorGetter) {
+	c.floatCounters[n] = g
+}
+
+func (c *StaticCollector) RegisterFloatGauge(n string, g Float64VectorGetter) {
+	c.floatGauges[n] = g
+}
+
 func (c *StaticCollector) RegisterHistogram(n string, g HistogramVectorGetter) {
 	c.histograms[n] = g
 }
 
+func (c *StaticCollector) RegisterSummary(n string, g SummaryVectorGetter) {
+	c.summaries[n] = g
+}
+
 // Int64Snapshot represents a snapshot of a counter or gauge value.
 type Int64Snapshot struct {
-	Name   string
-	Labels map[string]string
-	Value  int64
+	Name     string
+	Labels   map[string]string
+	Value    int64
+	Metadata Metadata
 }
 
 func int64snapshots(n string, g Int64VectorGetter) []Int64Snapshot {
-	var sns []Int64Snapshot
+	var (
+		sns []Int64Snapshot
+		md  = GetMetadata(g)
+	)
 
 	for _, v := range g.Get() {
 		sns = append(
 			sns,
 			Int64Snapshot{
-				Name:   n,
-				Labels: v.Tags,
-				Value:  v.Value,
+				Name:     n,
+				Labels:   v.Tags,
+				Value:    v.Value,
+				Metadata: md,
+			},
+		)
+	}
+
+	return sns
+}
+
+// Float64Snapshot represents a snapshot of a float counter or float gauge value.
+type Float64Snapshot struct {
+	Name     string
+	Labels   map[string]string
+	Value    float64
+	Metadata Metadata
+}
+
+func float64snapshots(n string, g Float64VectorGetter) []Float64Snapshot {
+	var (
+		sns []Float64Snapshot
+		md  = GetMetadata(g)
+	)
+
+	for _, v := range g.Get() {
+		sns = append(
+			sns,
+			Float64Snapshot{
+				Name:     n,
+				Labels:   v.Tags,
+				Value:    v.Value,
+				Metadata: md,
 			},
 		)
 	}
@@ -63,15 +115,26 @@ func int64snapshots(n string, g Int64VectorGetter) []Int64Snapshot {
 
 // HistogramSnapshot represents a snapshot of a histogram value.
 type HistogramSnapshot struct {
-	Name  string
-	Value HistogramValue
+	Name     string
+	Value    HistogramValue
+	Metadata Metadata
+}
+
+// SummarySnapshot represents a snapshot of a summary value.
+type SummarySnapshot struct {
+	Name     string
+	Value    SummaryValue
+	Metadata Metadata
 }
 
 // Snapshot contains all metric values at a point in time.
 type Snapshot struct {
-	Counters   []Int64Snapshot
-	Gauges     []Int64Snapshot
-	Histograms []HistogramSnapshot
+	Counters      []Int64Snapshot
+	Gauges        []Int64Snapshot
+	FloatCounters []Float64Snapshot
+	FloatGauges   []Float64Snapshot
+	Histograms    []HistogramSnapshot
+	Summaries     []SummarySnapshot
 }
 
 func compareLabels(x, y map[string]string) int {
@@ -99,6 +162,22 @@ func (ss Int64Snapshots) Swap(i int, j int) {
 	ss[j], ss[i] = ss[i], ss[j]
 }
 
+type Float64Snapshots []Float64Snapshot
+
+func (ss Float64Snapshots) Len() int { return len(ss) }
+
+func (ss Float64Snapshots) Less(i int, j int) bool {
+	if ss[i].Name != ss[j].Name {
+		return ss[i].Name < ss[j].Name
+	}
+
+	return compareLabels(ss[i].Labels, ss[j].Labels) < 0
+}
+
+func (ss Float64Snapshots) Swap(i int, j int) {
+	ss[j], ss[i] = ss[i], ss[j]
+}
+
 type HistogramSnapshots []HistogramSnapshot
 
 func (ss HistogramSnapshots) Len() int { return len(ss) }
@@ -115,12 +194,30 @@ func (ss HistogramSnapshots) Swap(i int, j int) {
 	ss[j], ss[i] = ss[i], ss[j]
 }
 
+type SummarySnapshots []SummarySnapshot
+
+func (ss SummarySnapshots) Len() int { return len(ss) }
+
+func (ss SummarySnapshots) Less(i int, j int) bool {
+	if ss[i].Name != ss[j].Name {
+		return ss[i].Name < ss[j].Name
+	}
+
+	return compareLabels(ss[i].Value.Tags, ss[j].Value.Tags) < 0
+}
+
+func (ss SummarySnapshots) Swap(i int, j int) {
+	ss[j], ss[i] = ss[i], ss[j]
+}
+
 // Get returns a sorted snapshot of all metric values.
 // Useful for testing and assertions.
 func (c *StaticCollector) Get() Snapshot {
 	var (
-		counters, gauges []Int64Snapshot
-		histograms       []HistogramSnapshot
+		counters, gauges           []Int64Snapshot
+		floatCounters, floatGauges []Float64Snapshot
+		histograms                 []HistogramSnapshot
+		summaries                  []SummarySnapshot
 	)
 
 	for n, g := range c.counters {
@@ -131,15 +228,49 @@ func (c *StaticCollector) Get() Snapshot {
 		gauges = append(gauges, int64snapshots(n, g)...)
 	}
 
+	for n, g := range c.floatCounters {
+		floatCounters = append(floatCounters, float64snapshots(n, g)...)
+	}
+
+	for n, g := range c.floatGauges {
+		floatGauges = append(floatGauges, float64snapshots(n, g)...)
+	}
+
 	for n, g := range c.histograms {
+		md := GetMetadata(g)
+
 		for _, v := range g.Get() {
-			histograms = append(histograms, HistogramSnapshot{Name: n, Value: *v})
+			histograms = append(
+				histograms,
+				HistogramSnapshot{Name: n, Value: *v, Metadata: md},
+			)
+		}
+	}
+
+	for n, g := range c.summaries {
+		md := GetMetadata(g)
+
+		for _, v := range g.Get() {
+			summaries = append(
+				summaries,
+				SummarySnapshot{Name: n, Value: *v, Metadata: md},
+			)
 		}
 	}
 
 	sort.Sort(Int64Snapshots(counters))
 	sort.Sort(Int64Snapshots(gauges))
+	sort.Sort(Float64Snapshots(floatCounters))
+	sort.Sort(Float64Snapshots(floatGauges))
 	sort.Sort(HistogramSnapshots(histograms))
+	sort.Sort(SummarySnapshots(summaries))
 
-	return Snapshot{Counters: counters, Gauges: gauges, Histograms: histograms}
+	return Snapshot{
+		Counters:      counters,
+		Gauges:        gauges,
+		FloatCounters: floatCounters,
+		FloatGauges:   floatGauges,
+		Histograms:    histograms,
+		Summaries:     summaries,
+	}
 }
@@ -0,0 +1,627 @@
+package statsd
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/upfluence/log"
+
+	"github.com/upfluence/stats"
+)
+
+// HistogramMode defines how histograms are translated into statsd lines.
+type HistogramMode int
+
+const (
+	// HistogramBuckets emits one counter per bucket holding the number of
+	// observations since the last flush, tagged with the bucket upper bound,
+	// along with the count and sum deltas.
+	HistogramBuckets HistogramMode = iota
+
+	// HistogramTimings emits one synthesized timing sample per non-empty
+	// bucket, the number of observations being carried by the sample rate.
+	// Values are expected to be in seconds (as recorded by stats.Timer) and
+	// are sent in milliseconds.
+	HistogramTimings
+)
+
+var defaultOptions = options{
+	interval:      10 * time.Second,
+	maxPacketSize: 1432,
+	histogramMode: HistogramBuckets,
+}
+
+type options struct {
+	interval      time.Duration
+	prefix        string
+	tags          map[string]string
+	maxPacketSize int
+	histogramMode HistogramMode
+
+	logger log.Logger
+}
+
+// Option configures a Collector with custom settings.
+type Option func(*options)
+
+// WithInterval configures the delay between two flushes. Default is 10s.
+func WithInterval(d time.Duration) Option {
+	return func(opts *options) { opts.interval = d }
+}
+
+// WithPrefix configures a prefix prepended to every metric name, separated
+// by a dot.
+func WithPrefix(p string) Option {
+	return func(opts *options) { opts.prefix = p }
+}
+
+// WithTags configures tags added to every emitted line.
+func WithTags(tags map[string]string) Option {
+	return func(opts *options) { opts.tags = tags }
+}
+
+// WithMaxPacketSize configures the maximum size of an UDP datagram.
+// Default is 1432 bytes, which fits in a standard ethernet MTU.
+func WithMaxPacketSize(s int) Option {
+	return func(opts *options) { opts.maxPacketSize = s }
+}
+
+// WithHistogramMode configures how the histograms are emitted.
+// Default is HistogramBuckets.
+func WithHistogramMode(m HistogramMode) Option {
+	return func(opts *options) { opts.histogramMode = m }
+}
+
+// WithLogger configures a logger used to report flush failures.
+func WithLogger(l log.Logger) Option {
+	return func(opts *options) { opts.logger = l }
+}
+
+type histogramState struct {
+	sum     float64
+	buckets []int64
+}
+
+// Collector periodically emits the registered metrics as StatsD lines over
+// UDP, using the DogStatsD syntax for the labels.
+//
+// Counters are sent as deltas since the previous flush, gauges as absolute
+// values.
+type Collector struct {
+	opts options
+	conn io.WriteCloser
+
+	mu            sync.Mutex
+	counters      map[string][]stats.Int64VectorGetter
+	gauges        map[string][]stats.Int64VectorGetter
+	floatCounters map[string][]stats.Float64VectorGetter
+	floatGauges   map[string][]stats.Float64VectorGetter
+	histograms    map[string][]stats.HistogramVectorGetter
+
+	lastCounters      map[string]map[string]int64
+	lastFloatCounters map[string]map[string]float64
+	lastHistograms    map[string]map[string]histogramState
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCollector creates a collector sending its metrics to the given UDP
+// address and starts flushing right away.
+func NewCollector(addr string, cOpts ...Option) (*Collector, error) {
+	conn, err := net.Dial("udp", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newCollector(conn, cOpts...), nil
+}
+
+func newCollector(conn io.WriteCloser, cOpts ...Option) *Collector {
+	var opts = defaultOptions
+
+	for _, opt := range cOpts {
+		opt(&opts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Collector{
+		opts:              opts,
+		conn:              conn,
+		counters:          make(map[string][]stats.Int64VectorGetter),
+		gauges:            make(map[string][]stats.Int64VectorGetter),
+		floatCounters:     make(map[string][]stats.Float64VectorGetter),
+		floatGauges:       make(map[string][]stats.Float64VectorGetter),
+		histograms:        make(map[string][]stats.HistogramVectorGetter),
+		lastCounters:      make(map[string]map[string]int64),
+		lastFloatCounters: make(map[string]map[string]float64),
+		lastHistograms:    make(map[string]map[string]histogramState),
+		cancel:            cancel,
+		done:              make(chan struct{}),
+	}
+
+	go c.run(ctx)
+
+	return c
+}
+
+func (c *Collector) run(ctx context.Context) {
+	defer close(c.done)
+
+	t := time.NewTicker(c.opts.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := c.flush(); err != nil && c.opts.logger != nil {
+				c.opts.logger.WithError(err).Error("failed to flush the statsd metrics")
+			}
+		}
+	}
+}
+
+// Close stops the periodic flushes, performs a final flush and closes the
+// underlying connection.
+func (c *Collector) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		c.cancel()
+		<-c.done
+
+		err = c.flush()
+
+		if cerr := c.conn.Close(); err == nil {
+			err = cerr
+		}
+	})
+
+	return err
+}
+
+func (c *Collector) RegisterCounter(n string, g stats.Int64VectorGetter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters[n] = append(c.counters[n], g)
+
+	if _, ok := c.lastCounters[n]; !ok {
+		c.lastCounters[n] = make(map[string]int64)
+	}
+}
+
+func (c *Collector) RegisterGauge(n string, g stats.Int64VectorGetter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gauges[n] = append(c.gauges[n], g)
+}
+
+func (c *Collector) RegisterFloatCounter(n string, g stats.Float64VectorGetter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.floatCounters[n] = append(c.floatCounters[n], g)
+
+	if _, ok := c.lastFloatCounters[n]; !ok {
+		c.lastFloatCounters[n] = make(map[string]float64)
+	}
+}
+
+func (c *Collector) RegisterFloatGauge(n string, g stats.Float64VectorGetter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.floatGauges[n] = append(c.floatGauges[n], g)
+}
+
+func (c *Collector) RegisterHistogram(n string, g stats.HistogramVectorGetter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gs := c.histograms[n]; len(gs) > 0 {
+		if !equalFloat64Slices(gs[0].Cutoffs(), g.Cutoffs()) {
+			panic(
+				&stats.ConflictError{
+					Name: n,
+					Kind: stats.CutoffsConflict,
+					Has:  g.Cutoffs(),
+					Want: gs[0].Cutoffs(),
+				},
+			)
+		}
+	} else {
+		c.lastHistograms[n] = make(map[string]histogramState)
+	}
+
+	c.histograms[n] = append(c.histograms[n], g)
+}
+
+// Unregister removes the getter registered under the name n, the state kept
+// to compute the deltas of n is dropped along with its last getter.
+func (c *Collector) Unregister(n string, g interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if removeGetter(c.counters, n, g) {
+		delete(c.lastCounters, n)
+	}
+
+	removeGetter(c.gauges, n, g)
+
+	if removeGetter(c.floatCounters, n, g) {
+		delete(c.lastFloatCounters, n)
+	}
+
+	removeGetter(c.floatGauges, n, g)
+
+	if removeGetter(c.histograms, n, g) {
+		delete(c.lastHistograms, n)
+	}
+}
+
+// removeGetter removes g from the getters of n, it returns true if g was the
+// last getter of n.
+func removeGetter[T any](gs map[string][]T, n string, g interface{}) bool {
+	for i, cg := range gs[n] {
+		if interface{}(cg) != g {
+			continue
+		}
+
+		if len(gs[n]) == 1 {
+			delete(gs, n)
+			return true
+		}
+
+		gs[n] = append(gs[n][:i:i], gs[n][i+1:]...)
+
+		return false
+	}
+
+	return false
+}
+
+func (c *Collector) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pw := packetWriter{w: c.conn, max: c.opts.maxPacketSize}
+
+	for n, gs := range c.counters {
+		c.writeCounters(&pw, n, gs)
+	}
+
+	for n, gs := range c.gauges {
+		for _, v := range aggregateInt64Values(gs, false) {
+			pw.writeLine(c.formatLine(n, formatInt64(v.Value), "g", 1, v.Tags))
+		}
+	}
+
+	for n, gs := range c.floatCounters {
+		c.writeFloatCounters(&pw, n, gs)
+	}
+
+	for n, gs := range c.floatGauges {
+		for _, v := range aggregateFloat64Values(gs, false) {
+			pw.writeLine(c.formatLine(n, formatFloat64(v.Value), "g", 1, v.Tags))
+		}
+	}
+
+	for n, gs := range c.histograms {
+		c.writeHistograms(&pw, n, gs)
+	}
+
+	return pw.flush()
+}
+
+func (c *Collector) writeCounters(pw *packetWriter, n string, gs []stats.Int64VectorGetter) {
+	var (
+		vs   = aggregateInt64Values(gs, true)
+		last = c.lastCounters[n]
+		next = make(map[string]int64, len(vs))
+	)
+
+	for k, v := range vs {
+		d := v.Value - last[k]
+
+		if d < 0 {
+			d = v.Value
+		}
+
+		next[k] = v.Value
+
+		if d != 0 {
+			pw.writeLine(c.formatLine(n, formatInt64(d), "c", 1, v.Tags))
+		}
+	}
+
+	// Only the state of the series still alive is kept.
+	c.lastCounters[n] = next
+}
+
+func (c *Collector) writeFloatCounters(pw *packetWriter, n string, gs []stats.Float64VectorGetter) {
+	var (
+		vs   = aggregateFloat64Values(gs, true)
+		last = c.lastFloatCounters[n]
+		next = make(map[string]float64, len(vs))
+	)
+
+	for k, v := range vs {
+		d := v.Value - last[k]
+
+		if d < 0 {
+			d = v.Value
+		}
+
+		next[k] = v.Value
+
+		if d != 0 {
+			pw.writeLine(c.formatLine(n, formatFloat64(d), "c", 1, v.Tags))
+		}
+	}
+
+	c.lastFloatCounters[n] = next
+}
+
+func (c *Collector) writeHistograms(pw *packetWriter, n string, gs []stats.HistogramVectorGetter) {
+	var (
+		vs      = aggregateHistogramValues(gs)
+		last    = c.lastHistograms[n]
+		next    = make(map[string]histogramState, len(vs))
+		cutoffs = gs[0].Cutoffs()
+	)
+
+	for k, v := range vs {
+		var (
+			prev = last[k]
+			cur  = histogramState{sum: v.Sum, buckets: make([]int64, len(v.Buckets))}
+
+			deltas = make([]int64, len(v.Buckets))
+			reset  = len(prev.buckets) != len(v.Buckets)
+
+			count int64
+		)
+
+		for i, b := range v.Buckets {
+			cur.buckets[i] = b.Count
+
+			if !reset {
+				deltas[i] = b.Count - prev.buckets[i]
+				reset = deltas[i] < 0
+			}
+		}
+
+		if reset {
+			copy(deltas, cur.buckets)
+			prev = histogramState{}
+		}
+
+		next[k] = cur
+
+		for _, d := range deltas {
+			count += d
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		switch c.opts.histogramMode {
+		case HistogramTimings:
+			for i, d := range deltas {
+				if d == 0 {
+					continue
+				}
+
+				pw.writeLine(
+					c.formatLine(
+						n,
+						formatFloat64(bucketValue(cutoffs, i)*1000),
+						"ms",
+						1/float64(d),
+						v.Tags,
+					),
+				)
+			}
+		default:
+			for i, d := range deltas {
+				if d == 0 {
+					continue
+				}
+
+				pw.writeLine(
+					c.formatLine(
+						n+"_bucket",
+						formatInt64(d),
+						"c",
+						1,
+						v.Tags,
+						tag{key: "le", value: formatFloat64(v.Buckets[i].UpperBound)},
+					),
+				)
+			}
+
+			pw.writeLine(c.formatLine(n+"_count", formatInt64(count), "c", 1, v.Tags))
+			pw.writeLine(
+				c.formatLine(n+"_sum", formatFloat64(cur.sum-prev.sum), "c", 1, v.Tags),
+			)
+		}
+	}
+
+	c.lastHistograms[n] = next
+}
+
+// bucketValue returns the value representing the observations of the i-th
+// bucket, the overflow bucket is represented by the highest finite cutoff.
+func bucketValue(cutoffs []float64, i int) float64 {
+	for ; i >= 0; i-- {
+		if !math.IsInf(cutoffs[i], 0) {
+			return cutoffs[i]
+		}
+	}
+
+	return 0
+}
+
+func aggregateInt64Values(gs []stats.Int64VectorGetter, sum bool) map[string]*stats.Int64Value {
+	var res = make(map[string]*stats.Int64Value)
+
+	for _, g := range gs {
+		for _, v := range g.Get() {
+			k := tagsKey(v.Tags)
+
+			if cur, ok := res[k]; ok {
+				if sum {
+					cur.Value += v.Value
+				}
+
+				continue
+			}
+
+			res[k] = &stats.Int64Value{Tags: v.Tags, Value: v.Value}
+		}
+	}
+
+	return res
+}
+
+func aggregateFloat64Values(gs []stats.Float64VectorGetter, sum bool) map[string]*stats.Float64Value {
+	var res = make(map[string]*stats.Float64Value)
+
+	for _, g := range gs {
+		for _, v := range g.Get() {
+			k := tagsKey(v.Tags)
+
+			if cur, ok := res[k]; ok {
+				if sum {
+					cur.Value += v.Value
+				}
+
+				continue
+			}
+
+			res[k] = &stats.Float64Value{Tags: v.Tags, Value: v.Value}
+		}
+	}
+
+	return res
+}
+
+func aggregateHistogramValues(gs []stats.HistogramVectorGetter) map[string]*stats.HistogramValue {
+	var res = make(map[string]*stats.HistogramValue)
+
+	for _, g := range gs {
+		for _, v := range g.Get() {
+			k := tagsKey(v.Tags)
+
+			cur, ok := res[k]
+
+			if !ok {
+				res[k] = &stats.HistogramValue{
+					Tags:    v.Tags,
+					Count:   v.Count,
+					Sum:     v.Sum,
+					Buckets: append([]stats.Bucket(nil), v.Buckets...),
+				}
+
+				continue
+			}
+
+			cur.Count += v.Count
+			cur.Sum += v.Sum
+
+			for i, b := range v.Buckets {
+				cur.Buckets[i].Count += b.Count
+			}
+		}
+	}
+
+	return res
+}
+
+func equalFloat64Slices(x, y []float64) bool {
+	if len(x) != len(y) {
+		return false
+	}
+
+	for i, v := range x {
+		if v != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type tag struct {
+	key   string
+	value string
+}
+
+func (c *Collector) formatLine(n, v, typ string, rate float64, tags map[string]string, extra ...tag) []byte {
+	var (
+		buf []byte
+		ts  = make([]tag, 0, len(c.opts.tags)+len(tags)+len(extra))
+	)
+
+	for k, v := range mergeStringMaps(c.opts.tags, tags) {
+		ts = append(ts, tag{key: k, value: v})
+	}
+
+	sort.Slice(ts, func(i, j int) bool { return ts[i].key < ts[j].key })
+
+	ts = append(ts, extra...)
+
+	if c.opts.prefix != "" {
+		buf = append(buf, sanitizeName(c.opts.prefix)...)
+		buf = append(buf, '.')
+	}
+
+	buf = append(buf, sanitizeName(n)...)
+	buf = append(buf, ':')
+	buf = append(buf, v...)
+	buf = append(buf, '|')
+	buf = append(buf, typ...)
+
+	if rate < 1 {
+		buf = append(buf, "|@"...)
+		buf = append(buf, formatFloat64(rate)...)
+	}
+
+	for i, t := range ts {
+		if i == 0 {
+			buf = append(buf, "|#"...)
+		} else {
+			buf = append(buf, ',')
+		}
+
+		buf = append(buf, sanitizeTagKey(t.key)...)
+		buf = append(buf, ':')
+		buf = append(buf, sanitizeTagValue(t.value)...)
+	}
+
+	return buf
+}
+
+func mergeStringMaps(kvs ...map[string]string) map[string]string {
+	var res = make(map[string]string)
+
+	for _, kv := range kvs {
+		for k, v := range kv {
+			res[k] = v
+		}
+	}
+
+	return res
+}
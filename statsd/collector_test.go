@@ -0,0 +1,318 @@
+package statsd
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/upfluence/stats"
+)
+
+type mockConn struct {
+	mu      sync.Mutex
+	packets []string
+	closed  bool
+}
+
+func (mc *mockConn) Write(p []byte) (int, error) {
+	mc.mu.Lock()
+	mc.packets = append(mc.packets, string(p))
+	mc.mu.Unlock()
+
+	return len(p), nil
+}
+
+func (mc *mockConn) Close() error {
+	mc.closed = true
+	return nil
+}
+
+func (mc *mockConn) lines() []string {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	var res []string
+
+	for _, p := range mc.packets {
+		res = append(res, strings.Split(p, "\n")...)
+	}
+
+	mc.packets = nil
+
+	sort.Strings(res)
+
+	return res
+}
+
+func TestFlush(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		opts   []Option
+		mutate func(stats.Scope)
+		want   []string
+	}{
+		{
+			name:   "no mutation",
+			mutate: func(stats.Scope) {},
+		},
+		{
+			name: "counter",
+			mutate: func(s stats.Scope) {
+				s.CounterVector("foo", []string{"bar"}).WithLabels("buz").Add(3)
+			},
+			want: []string{"foo:3|c|#bar:buz"},
+		},
+		{
+			name: "gauge with prefix and global tags",
+			opts: []Option{
+				WithPrefix("app"),
+				WithTags(map[string]string{"env": "test"}),
+			},
+			mutate: func(s stats.Scope) {
+				s.Scope("", map[string]string{"a": "b"}).Gauge("foo").Update(12)
+			},
+			want: []string{"app.foo:12|g|#a:b,env:test"},
+		},
+		{
+			name: "float counter and gauge",
+			mutate: func(s stats.Scope) {
+				s.FloatCounter("foo").Add(1.5)
+				s.FloatGauge("bar").Update(.37)
+			},
+			want: []string{"bar:0.37|g", "foo:1.5|c"},
+		},
+		{
+			name: "histogram buckets",
+			mutate: func(s stats.Scope) {
+				h := s.Histogram("foo", stats.StaticBuckets([]float64{1}))
+
+				h.Record(.5)
+				h.Record(.5)
+				h.Record(2)
+			},
+			want: []string{
+				"foo_bucket:1|c|#le:+Inf",
+				"foo_bucket:2|c|#le:1",
+				"foo_count:3|c",
+				"foo_sum:3|c",
+			},
+		},
+		{
+			name: "histogram timings",
+			opts: []Option{WithHistogramMode(HistogramTimings)},
+			mutate: func(s stats.Scope) {
+				h := s.Histogram("foo", stats.StaticBuckets([]float64{.1, .5}))
+
+				h.Record(.05)
+				h.Record(.05)
+				h.Record(.05)
+				h.Record(.05)
+				h.Record(2)
+			},
+			want: []string{"foo:100|ms|@0.25", "foo:500|ms"},
+		},
+		{
+			name: "sanitized names and tags",
+			mutate: func(s stats.Scope) {
+				s.GaugeVector("foo:bar", []string{"a|b"}).WithLabels("c,d:e").Update(1)
+			},
+			want: []string{"foo_bar:1|g|#a_b:c_d:e"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var mc mockConn
+
+			c := newCollector(&mc, append(tt.opts, WithInterval(time.Hour))...)
+
+			tt.mutate(stats.RootScope(c))
+
+			assert.Nil(t, c.flush())
+
+			if len(tt.want) == 0 {
+				assert.Empty(t, mc.lines())
+			} else {
+				assert.Equal(t, tt.want, mc.lines())
+			}
+
+			assert.Nil(t, c.Close())
+			assert.True(t, mc.closed)
+		})
+	}
+}
+
+func TestCounterDeltas(t *testing.T) {
+	var mc mockConn
+
+	c := newCollector(&mc, WithInterval(time.Hour))
+	defer c.Close()
+
+	counter := stats.RootScope(c).Counter("foo")
+	histogram := stats.RootScope(c).Histogram("bar", stats.StaticBuckets(nil))
+
+	counter.Add(5)
+	histogram.Record(1)
+
+	assert.Nil(t, c.flush())
+	assert.Equal(
+		t,
+		[]string{
+			"bar_bucket:1|c|#le:+Inf",
+			"bar_count:1|c",
+			"bar_sum:1|c",
+			"foo:5|c",
+		},
+		mc.lines(),
+	)
+
+	assert.Nil(t, c.flush())
+	assert.Empty(t, mc.lines())
+
+	counter.Add(2)
+	histogram.Record(3)
+
+	assert.Nil(t, c.flush())
+	assert.Equal(
+		t,
+		[]string{
+			"bar_bucket:1|c|#le:+Inf",
+			"bar_count:1|c",
+			"bar_sum:3|c",
+			"foo:2|c",
+		},
+		mc.lines(),
+	)
+}
+
+func TestMultiRegisterCounter(t *testing.T) {
+	var mc mockConn
+
+	c := newCollector(&mc, WithInterval(time.Hour))
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		stats.RootScope(c).Counter("foo").Inc()
+	}
+
+	assert.Nil(t, c.flush())
+	assert.Equal(t, []string{"foo:3|c"}, mc.lines())
+}
+
+func TestPacketSize(t *testing.T) {
+	var mc mockConn
+
+	c := newCollector(&mc, WithInterval(time.Hour), WithMaxPacketSize(16))
+	defer c.Close()
+
+	cv := stats.RootScope(c).CounterVector("foo", []string{"bar"})
+
+	cv.WithLabels("a").Inc()
+	cv.WithLabels("b").Inc()
+
+	assert.Nil(t, c.flush())
+
+	assert.Len(t, mc.packets, 2)
+}
+
+func TestNewCollector(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	defer conn.Close()
+
+	c, err := NewCollector(conn.LocalAddr().String(), WithInterval(time.Hour))
+	assert.Nil(t, err)
+
+	stats.RootScope(c).Gauge("foo").Update(42)
+
+	assert.Nil(t, c.Close())
+
+	var buf = make([]byte, 1024)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "foo:42|g", string(buf[:n]))
+}
+
+func TestDeletedSeries(t *testing.T) {
+	var mc mockConn
+
+	c := newCollector(&mc, WithInterval(time.Hour))
+	defer c.Close()
+
+	cv := stats.RootScope(c).CounterVector("foo", []string{"bar"})
+
+	cv.WithLabels("buz").Add(5)
+
+	assert.Nil(t, c.flush())
+	assert.Equal(t, []string{"foo:5|c|#bar:buz"}, mc.lines())
+
+	cv.Delete("buz")
+
+	assert.Nil(t, c.flush())
+	assert.Empty(t, mc.lines())
+	assert.Empty(t, c.lastCounters["foo"])
+
+	cv.WithLabels("buz").Add(7)
+
+	assert.Nil(t, c.flush())
+	assert.Equal(t, []string{"foo:7|c|#bar:buz"}, mc.lines())
+}
+
+func TestDetach(t *testing.T) {
+	var (
+		mc mockConn
+
+		c  = newCollector(&mc, WithInterval(time.Hour))
+		s1 = stats.RootScope(c)
+		s2 = stats.RootScope(c)
+	)
+
+	defer c.Close()
+
+	s1.Counter("foo").Add(2)
+	s2.Counter("foo").Inc()
+	s1.FloatCounter("bar").Add(.5)
+	s1.Histogram("biz", stats.StaticBuckets(nil)).Record(1)
+
+	stats.Detach(s1)
+
+	assert.Nil(t, c.flush())
+	assert.Equal(t, []string{"foo:1|c"}, mc.lines())
+	assert.NotContains(t, c.lastFloatCounters, "bar")
+	assert.NotContains(t, c.lastHistograms, "biz")
+
+	stats.Detach(s2)
+
+	assert.Nil(t, c.flush())
+	assert.Empty(t, mc.lines())
+	assert.Empty(t, c.counters)
+	assert.Empty(t, c.lastCounters)
+}
+
+func TestSwappedTagValues(t *testing.T) {
+	var mc mockConn
+
+	c := newCollector(&mc, WithInterval(time.Hour))
+	defer c.Close()
+
+	cv := stats.RootScope(c).CounterVector("foo", []string{"a", "b"})
+
+	cv.WithLabels("x", "y").Inc()
+	cv.WithLabels("y", "x").Add(2)
+	stats.RootScope(c).CounterVector("foo", []string{"a", "b"}).WithLabels("x", "y").Add(3)
+
+	assert.Nil(t, c.flush())
+	assert.Equal(t, []string{"foo:2|c|#a:y,b:x", "foo:4|c|#a:x,b:y"}, mc.lines())
+	assert.NotEqual(
+		t,
+		tagsKey(map[string]string{"a": "x", "b": "y"}),
+		tagsKey(map[string]string{"a": "y", "b": "x"}),
+	)
+}
@@ -0,0 +1,87 @@
+package statsd
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type packetWriter struct {
+	w   io.Writer
+	max int
+
+	buf []byte
+	err error
+}
+
+func (pw *packetWriter) writeLine(l []byte) {
+	if len(pw.buf) > 0 && len(pw.buf)+len(l)+1 > pw.max {
+		pw.flush()
+	}
+
+	if len(pw.buf) > 0 {
+		pw.buf = append(pw.buf, '\n')
+	}
+
+	pw.buf = append(pw.buf, l...)
+}
+
+func (pw *packetWriter) flush() error {
+	if len(pw.buf) > 0 {
+		if _, err := pw.w.Write(pw.buf); err != nil && pw.err == nil {
+			pw.err = err
+		}
+
+		pw.buf = pw.buf[:0]
+	}
+
+	return pw.err
+}
+
+var (
+	nameReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "\n", "_")
+
+	tagKeyReplacer = strings.NewReplacer(
+		":", "_",
+		"|", "_",
+		",", "_",
+		"#", "_",
+		"\n", "_",
+	)
+	tagValueReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+)
+
+func sanitizeName(s string) string     { return nameReplacer.Replace(s) }
+func sanitizeTagKey(s string) string   { return tagKeyReplacer.Replace(s) }
+func sanitizeTagValue(s string) string { return tagValueReplacer.Replace(s) }
+
+func formatInt64(v int64) string { return strconv.FormatInt(v, 10) }
+
+func formatFloat64(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// tagsKey serializes the sorted tags, it identifies the series of a metric
+// across the getters registered under its name.
+func tagsKey(tags map[string]string) string {
+	var (
+		b  strings.Builder
+		ks = make([]string, 0, len(tags))
+	)
+
+	for k := range tags {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	for _, k := range ks {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(tags[k])
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
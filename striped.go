@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+)
+
+const cacheLineSize = 64
+
+// stripedCell is padded to fill a whole cache line, so concurrent updates of
+// different cells do not invalidate each other.
+type stripedCell struct {
+	v int64
+	_ [cacheLineSize - 8]byte
+}
+
+// stripedInt64 is a counter spreading its increments across several cells,
+// the cell is picked randomly so concurrent increments rarely share a cache
+// line. Reading the counter sums all the cells.
+type stripedInt64 struct {
+	mask  uint32
+	cells []stripedCell
+}
+
+func newStripedInt64() *stripedInt64 {
+	n := 1
+
+	for n < runtime.GOMAXPROCS(0) {
+		n <<= 1
+	}
+
+	return &stripedInt64{mask: uint32(n - 1), cells: make([]stripedCell, n)}
+}
+
+func (si *stripedInt64) Inc() { si.Add(1) }
+
+func (si *stripedInt64) Add(v int64) {
+	atomic.AddInt64(&si.cells[rand.Uint32()&si.mask].v, v)
+}
+
+func (si *stripedInt64) Get() int64 {
+	var res int64
+
+	for i := range si.cells {
+		res += atomic.LoadInt64(&si.cells[i].v)
+	}
+
+	return res
+}
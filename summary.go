@@ -0,0 +1,368 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/upfluence/stats/internal/quantile"
+)
+
+var defaultObjectives = map[float64]float64{.5: .05, .9: .01, .99: .001}
+
+const (
+	defaultSummaryMaxAge     = 10 * time.Minute
+	defaultSummaryAgeBuckets = 5
+)
+
+// Quantile represents the value of a quantile of a summary.
+type Quantile struct {
+	Quantile float64
+	Value    float64
+}
+
+// SummaryValue represents a snapshot of a summary's state including its tags,
+// total count, sum, and quantiles computed over the sliding window.
+type SummaryValue struct {
+	Tags map[string]string
+
+	Count     int64
+	Sum       float64
+	Quantiles []Quantile
+}
+
+// SummaryVectorGetter provides read access to summary vectors for collectors.
+type SummaryVectorGetter interface {
+	// Labels returns the label names for this summary vector.
+	Labels() []string
+
+	// Objectives returns the tracked quantiles.
+	Objectives() []float64
+
+	// Get returns all summary values with their label combinations.
+	Get() []*SummaryValue
+}
+
+// SummaryOption configures a summary with custom settings.
+// MetricOption values are valid summary options.
+type SummaryOption interface {
+	applySummary(*summaryVector)
+}
+
+type summaryOptionFunc func(*summaryVector)
+
+func (fn summaryOptionFunc) applySummary(sv *summaryVector) { fn(sv) }
+
+// SummaryObjectives configures the tracked quantiles, mapped to their
+// allowed absolute error. Default is p50 (±0.05), p90 (±0.01) and
+// p99 (±0.001).
+func SummaryObjectives(objectives map[float64]float64) SummaryOption {
+	return summaryOptionFunc(func(sv *summaryVector) { sv.setObjectives(objectives) })
+}
+
+// SummaryMaxAge configures the duration of the sliding window the quantiles
+// are computed over. Default is 10 minutes, non-positive durations are
+// ignored.
+func SummaryMaxAge(d time.Duration) SummaryOption {
+	return summaryOptionFunc(func(sv *summaryVector) {
+		if d > 0 {
+			sv.maxAge = d
+		}
+	})
+}
+
+// SummaryAgeBuckets configures the number of buckets the sliding window is
+// divided into, the window slides one bucket at a time. Default is 5,
+// non-positive values are ignored.
+func SummaryAgeBuckets(n int) SummaryOption {
+	return summaryOptionFunc(func(sv *summaryVector) {
+		if n > 0 {
+			sv.ageBuckets = n
+		}
+	})
+}
+
+// SummaryVector is a multi-dimensional summary that creates summary instances
+// with specific label values.
+type SummaryVector interface {
+	// WithLabels returns a Summary with the specified label values.
+	// The number of values must match the number of labels defined for this vector.
+	WithLabels(...string) Summary
+
+	// Bind returns the Summary of the specified label values like WithLabels,
+	// and pins its series so it is never evicted by WithTTL. The Summary is
+	// meant to be kept and reused instead of looking the series up again.
+	Bind(...string) Summary
+
+	// Delete removes the summary with the specified label values, it returns
+	// false if no such summary exists. The summaries previously returned by
+	// WithLabels for these values are detached from the vector.
+	Delete(...string) bool
+
+	// Reset removes all the summaries of the vector, including the ones
+	// created from other scopes registering the same name.
+	Reset()
+}
+
+// Summary tracks the quantiles of the observed values over a sliding time
+// window. Contrary to histograms, summaries do not need any bucket to be
+// configured, but their quantiles can not be aggregated across instances.
+type Summary interface {
+	// Record adds a single observation to the summary.
+	Record(float64)
+
+	// Count returns the total number of observations.
+	Count() int64
+
+	// Sum returns the sum of all observed values.
+	Sum() float64
+
+	// Quantiles returns the current value of the tracked quantiles, NaN is
+	// returned for every quantile if no value was observed in the window.
+	Quantiles() []Quantile
+}
+
+type summaryVector struct {
+	entityVector
+
+	targets    []quantile.Target
+	maxAge     time.Duration
+	ageBuckets int
+}
+
+func newSummaryVector(ls []string, lm labelMarshaler, opts ...SummaryOption) *summaryVector {
+	sv := &summaryVector{
+		entityVector: entityVector{labels: ls, marshaler: lm, now: time.Now},
+		maxAge:       defaultSummaryMaxAge,
+		ageBuckets:   defaultSummaryAgeBuckets,
+	}
+
+	sv.setObjectives(defaultObjectives)
+
+	for _, opt := range opts {
+		opt.applySummary(sv)
+	}
+
+	sv.newFunc = sv.newSummary
+
+	return sv
+}
+
+func (sv *summaryVector) setObjectives(objectives map[float64]float64) {
+	sv.targets = make([]quantile.Target, 0, len(objectives))
+
+	for q, e := range objectives {
+		sv.targets = append(sv.targets, quantile.Target{Quantile: q, Epsilon: e})
+	}
+
+	sort.Slice(
+		sv.targets,
+		func(i, j int) bool { return sv.targets[i].Quantile < sv.targets[j].Quantile },
+	)
+}
+
+func (sv *summaryVector) newSummary(map[string]string) interface{} {
+	s := &summary{
+		targets:     sv.targets,
+		streams:     make([]*quantile.Stream, sv.ageBuckets),
+		step:        sv.maxAge / time.Duration(sv.ageBuckets),
+		now:         sv.now,
+		headExpires: sv.now().Add(sv.maxAge),
+	}
+
+	for i := range s.streams {
+		s.streams[i] = quantile.NewStream(sv.targets)
+	}
+
+	return s
+}
+
+func (sv *summaryVector) Labels() []string   { return sv.labels }
+func (sv *summaryVector) Metadata() Metadata { return sv.metadata }
+
+func (sv *summaryVector) Objectives() []float64 {
+	var res = make([]float64, len(sv.targets))
+
+	for i, t := range sv.targets {
+		res[i] = t.Quantile
+	}
+
+	return res
+}
+
+func (sv *summaryVector) Get() []*SummaryValue {
+	var res []*SummaryValue
+
+	sv.rangeEntities(func(tags map[string]string, e interface{}) {
+		s := e.(*summary)
+
+		s.mu.Lock()
+		count, sum, qs := s.count, s.sum, s.quantiles()
+		s.mu.Unlock()
+
+		res = append(
+			res,
+			&SummaryValue{Tags: tags, Count: count, Sum: sum, Quantiles: qs},
+		)
+	})
+
+	return res
+}
+
+func (sv *summaryVector) WithLabels(ls ...string) Summary {
+	return sv.entity(ls).(*summary)
+}
+
+func (sv *summaryVector) Bind(ls ...string) Summary {
+	return sv.bind(ls).(*summary)
+}
+
+// summary keeps one quantile stream per age bucket, every stream receives all
+// the observations and the oldest one, covering the whole window, is queried.
+// The oldest stream is reset and becomes the newest one at every step.
+type summary struct {
+	targets []quantile.Target
+	step    time.Duration
+	now     func() time.Time
+
+	mu          sync.Mutex
+	count       int64
+	sum         float64
+	streams     []*quantile.Stream
+	head        int
+	headExpires time.Time
+}
+
+func (s *summary) rotate() {
+	var (
+		now    = s.now()
+		maxAge = s.step * time.Duration(len(s.streams))
+	)
+
+	if now.Sub(s.headExpires) >= maxAge {
+		for _, st := range s.streams {
+			st.Reset()
+		}
+
+		s.headExpires = now.Add(maxAge)
+
+		return
+	}
+
+	for !now.Before(s.headExpires) {
+		s.streams[s.head].Reset()
+		s.head = (s.head + 1) % len(s.streams)
+		s.headExpires = s.headExpires.Add(s.step)
+	}
+}
+
+func (s *summary) Record(v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rotate()
+
+	s.count++
+	s.sum += v
+
+	for _, st := range s.streams {
+		st.Insert(v)
+	}
+}
+
+func (s *summary) Count() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.count
+}
+
+func (s *summary) Sum() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sum
+}
+
+func (s *summary) Quantiles() []Quantile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.quantiles()
+}
+
+func (s *summary) quantiles() []Quantile {
+	s.rotate()
+
+	var (
+		st  = s.streams[s.head]
+		res = make([]Quantile, len(s.targets))
+	)
+
+	for i, t := range s.targets {
+		res[i] = Quantile{Quantile: t.Quantile, Value: st.Query(t.Quantile)}
+	}
+
+	return res
+}
+
+type partialSummaryVector struct {
+	sv SummaryVector
+	vs []string
+}
+
+func (psv partialSummaryVector) WithLabels(ls ...string) Summary {
+	return withLabelValues(psv.vs, ls, psv.sv.WithLabels)
+}
+
+func (psv partialSummaryVector) Bind(ls ...string) Summary {
+	return withLabelValues(psv.vs, ls, psv.sv.Bind)
+}
+
+func (psv partialSummaryVector) Delete(ls ...string) bool {
+	return withLabelValues(psv.vs, ls, psv.sv.Delete)
+}
+
+func (psv partialSummaryVector) Reset() { psv.sv.Reset() }
+
+type reorderSummaryVector struct {
+	sv SummaryVector
+	labelOrderer
+}
+
+func (rsv reorderSummaryVector) WithLabels(ls ...string) Summary {
+	return rsv.sv.WithLabels(rsv.order(ls)...)
+}
+
+func (rsv reorderSummaryVector) Bind(ls ...string) Summary {
+	return rsv.sv.Bind(rsv.order(ls)...)
+}
+
+func (rsv reorderSummaryVector) Delete(ls ...string) bool {
+	return rsv.sv.Delete(rsv.order(ls)...)
+}
+
+func (rsv reorderSummaryVector) Reset() { rsv.sv.Reset() }
+
+var (
+	// NoopSummary is a summary that discards all operations.
+	NoopSummary Summary = noopSummary{}
+
+	// NoopSummaryVector is a summary vector that returns noop summaries.
+	NoopSummaryVector SummaryVector = noopSummaryVector{}
+)
+
+type noopSummary struct{}
+
+func (noopSummary) Record(float64)        {}
+func (noopSummary) Count() int64          { return 0 }
+func (noopSummary) Sum() float64          { return 0 }
+func (noopSummary) Quantiles() []Quantile { return nil }
+
+type noopSummaryVector struct{}
+
+func (noopSummaryVector) WithLabels(...string) Summary { return noopSummary{} }
+
+func (noopSummaryVector) Bind(...string) Summary { return noopSummary{} }
+func (noopSummaryVector) Delete(...string) bool  { return false }
+func (noopSummaryVector) Reset()                 {}
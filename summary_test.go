@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	c := NewStaticCollector()
+	s := RootScope(c).Scope("bar", map[string]string{"fiz": "buz"})
+
+	sv := s.SummaryVector(
+		"foo",
+		[]string{"method"},
+		SummaryObjectives(map[float64]float64{.5: .01, .9: .01}),
+	)
+
+	for i := 1; i <= 100; i++ {
+		sv.WithLabels("GET").Record(float64(i))
+	}
+
+	assert.Equal(
+		t,
+		[]SummarySnapshot{
+			{
+				Name: "bar_foo",
+				Value: SummaryValue{
+					Tags:  map[string]string{"fiz": "buz", "method": "GET"},
+					Count: 100,
+					Sum:   5050,
+					Quantiles: []Quantile{
+						{Quantile: .5, Value: 50},
+						{Quantile: .9, Value: 90},
+					},
+				},
+			},
+		},
+		c.Get().Summaries,
+	)
+}
+
+func TestSummaryWindow(t *testing.T) {
+	var (
+		now = time.Unix(0, 0)
+
+		sv = newSummaryVector(
+			nil,
+			newDefaultMarshaler(),
+			SummaryMaxAge(time.Minute),
+			SummaryAgeBuckets(2),
+		)
+	)
+
+	sv.now = func() time.Time { return now }
+
+	s := sv.WithLabels()
+
+	s.Record(1)
+	s.Record(1)
+	s.Record(1)
+
+	now = now.Add(75 * time.Second)
+	s.Record(10)
+
+	assert.Equal(t, 1., s.Quantiles()[0].Value)
+
+	now = now.Add(20 * time.Second)
+
+	assert.Equal(t, 10., s.Quantiles()[0].Value)
+	assert.Equal(t, int64(4), s.Count())
+	assert.Equal(t, 13., s.Sum())
+
+	now = now.Add(time.Hour)
+
+	assert.True(t, math.IsNaN(s.Quantiles()[0].Value))
+	assert.Equal(t, int64(4), s.Count())
+}
+
+func TestSummaryFallback(t *testing.T) {
+	c := NewStaticCollector()
+
+	RootScope(int64OnlyCollector{Collector: c}).Summary(
+		"foo",
+		SummaryObjectives(map[float64]float64{.5: .01}),
+	).Record(3)
+
+	assert.Equal(
+		t,
+		Snapshot{
+			Counters: []Int64Snapshot{
+				{Name: "foo_count", Labels: map[string]string{}, Value: 1},
+				{Name: "foo_sum", Labels: map[string]string{}, Value: 3},
+			},
+			Gauges: []Int64Snapshot{
+				{Name: "foo", Labels: map[string]string{"quantile": "0.5"}, Value: 3},
+			},
+		},
+		c.Get(),
+	)
+}
+
+func TestSummaryInvalidWindow(t *testing.T) {
+	c := NewStaticCollector()
+
+	s := RootScope(c).Summary(
+		"foo",
+		SummaryObjectives(map[float64]float64{.5: .01}),
+		SummaryMaxAge(0),
+		SummaryAgeBuckets(0),
+	)
+
+	s.Record(3)
+
+	v := c.Get().Summaries[0]
+
+	assert.Equal(t, int64(1), v.Value.Count)
+	assert.Equal(t, []Quantile{{Quantile: .5, Value: 3}}, v.Value.Quantiles)
+}
+
+func BenchmarkSummary(b *testing.B) {
+	s := RootScope(NewStaticCollector()).Summary("foo")
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.Record(float64(i % 100))
+	}
+}
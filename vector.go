@@ -3,6 +3,8 @@ package stats
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Int64Value represents a single int64 metric value with its associated tags.
@@ -21,45 +23,34 @@ type Int64VectorGetter interface {
 	Get() []*Int64Value
 }
 
+type int64Getter interface {
+	Get() int64
+}
+
 type atomicInt64Vector struct {
 	entityVector
 }
 
-func newAtomicInt64Vector(ls []string, lm labelMarshaler) *atomicInt64Vector {
+func newAtomicInt64Vector(ls []string, lm labelMarshaler, mo metricOptions) *atomicInt64Vector {
 	return &atomicInt64Vector{
 		entityVector: entityVector{
-			labels:    ls,
-			marshaler: lm,
-			newFunc:   func(map[string]string) interface{} { return &atomicInt64{} },
+			labels:        ls,
+			marshaler:     lm,
+			newFunc:       func(map[string]string) interface{} { return &atomicInt64{} },
+			now:           time.Now,
+			metricOptions: mo,
 		},
 	}
 }
 
-func (v *atomicInt64Vector) Labels() []string { return v.labels }
-
-func (v *atomicInt64Vector) buildTags(key uint64) map[string]string {
-	var tags = make(map[string]string, len(v.labels))
-
-	for i, val := range v.marshaler.unmarshal(key, len(v.labels)) {
-		tags[v.labels[i]] = val
-	}
-
-	return tags
-}
+func (v *atomicInt64Vector) Labels() []string   { return v.labels }
+func (v *atomicInt64Vector) Metadata() Metadata { return v.metadata }
 
 func (v *atomicInt64Vector) Get() []*Int64Value {
 	var res []*Int64Value
 
-	v.entities.Range(func(k, vv interface{}) bool {
-		res = append(
-			res,
-			&Int64Value{
-				Tags:  v.buildTags(k.(uint64)),
-				Value: vv.(*atomicInt64).Get(),
-			},
-		)
-
-		return true
+	v.rangeEntities(func(tags map[string]string, e interface{}) {
+		res = append(res, &Int64Value{Tags: tags, Value: e.(int64Getter).Get()})
 	})
 
 	return res
@@ -69,16 +60,65 @@ func (v *atomicInt64Vector) fetchValue(ls []string) *atomicInt64 {
 	return v.entity(ls).(*atomicInt64)
 }
 
+const (
+	// OverflowLabelValue is the value of every label of the series gathering
+	// the values of vectors having reached their maximum number of series.
+	OverflowLabelValue = "__overflow__"
+
+	// CardinalityOverflowMetric is the name of the counter, labeled by metric
+	// name, of the WithLabels calls routed to an overflow series.
+	CardinalityOverflowMetric = "stats_cardinality_overflows_total"
+)
+
+var labelValuesPool = sync.Pool{New: func() interface{} { return new([]string) }}
+
+// withLabelValues calls fn with the label values of the scope vs followed by
+// the ones of the caller ls. The values are concatenated into a pooled buffer
+// so fn must not retain them.
+func withLabelValues[T any](vs, ls []string, fn func(...string) T) T {
+	if len(vs) == 0 {
+		return fn(ls...)
+	}
+
+	buf := labelValuesPool.Get().(*[]string)
+	*buf = append(append((*buf)[:0], vs...), ls...)
+
+	defer func() {
+		clear(*buf)
+		labelValuesPool.Put(buf)
+	}()
+
+	return fn(*buf...)
+}
+
 type entityVector struct {
-	newFunc func(map[string]string) interface{}
+	metricOptions
+
+	newFunc    func(map[string]string) interface{}
+	onOverflow func()
 
 	labels   []string
 	entities sync.Map
+	size     int64
 
 	marshaler labelMarshaler
+
+	now func() time.Time
 }
 
-func (ev *entityVector) entity(ls []string) interface{} {
+// entityEntry holds a series of the vector, created is the time the series
+// was created, touched is the last time the series was accessed and is only
+// maintained for vectors with a ttl. Pinned series are not evicted by the ttl.
+// The overflow series does not count toward the maximum number of series.
+type entityEntry struct {
+	value    interface{}
+	created  int64
+	touched  int64
+	pinned   int32
+	overflow bool
+}
+
+func (ev *entityVector) assertLabelCount(ls []string) {
 	if len(ls) != len(ev.labels) {
 		panic(
 			fmt.Sprintf(
@@ -88,20 +128,281 @@ func (ev *entityVector) entity(ls []string) interface{} {
 			),
 		)
 	}
+}
+
+func (ev *entityVector) timestamp() int64 {
+	if ev.now == nil {
+		return time.Now().UnixNano()
+	}
+
+	return ev.now().UnixNano()
+}
+
+func (ev *entityVector) touch(e *entityEntry) {
+	if ev.ttl > 0 {
+		atomic.StoreInt64(&e.touched, ev.timestamp())
+	}
+}
+
+func (ev *entityVector) load(ls []string) (uint64, *entityEntry, bool) {
+	k, ok := ev.marshaler.marshal(ls)
+
+	if !ok {
+		return 0, nil, false
+	}
 
-	k := ev.marshaler.marshal(ls)
 	v, ok := ev.entities.Load(k)
 
-	if ok {
-		return v
+	if !ok {
+		return 0, nil, false
 	}
 
+	return k, v.(*entityEntry), true
+}
+
+func (ev *entityVector) entity(ls []string) interface{} {
+	return ev.entry(ls).value
+}
+
+// bind returns the value of the series and pins it.
+func (ev *entityVector) bind(ls []string) interface{} {
+	e := ev.entry(ls)
+	atomic.StoreInt32(&e.pinned, 1)
+
+	return e.value
+}
+
+func (ev *entityVector) entry(ls []string) *entityEntry {
+	ev.assertLabelCount(ls)
+
+	if _, e, ok := ev.load(ls); ok {
+		ev.touch(e)
+		return e
+	}
+
+	if !ev.reserve() {
+		if ev.onOverflow != nil {
+			ev.onOverflow()
+		}
+
+		return ev.overflowEntity()
+	}
+
+	return ev.store(ls, false)
+}
+
+// reserve accounts for a new series, it returns false if the vector already
+// holds its maximum number of series.
+func (ev *entityVector) reserve() bool {
+	n := atomic.AddInt64(&ev.size, 1)
+
+	if ev.maxSeries > 0 && n > ev.maxSeries {
+		atomic.AddInt64(&ev.size, -1)
+		return false
+	}
+
+	return true
+}
+
+func (ev *entityVector) overflowEntity() *entityEntry {
+	var ls = make([]string, len(ev.labels))
+
+	for i := range ls {
+		ls[i] = OverflowLabelValue
+	}
+
+	if _, e, ok := ev.load(ls); ok {
+		ev.touch(e)
+		return e
+	}
+
+	return ev.store(ls, true)
+}
+
+func (ev *entityVector) store(ls []string, overflow bool) *entityEntry {
 	vs := make(map[string]string, len(ev.labels))
 
 	for i, k := range ev.labels {
 		vs[k] = ls[i]
 	}
 
-	v, _ = ev.entities.LoadOrStore(k, ev.newFunc(vs))
-	return v
+	e := &entityEntry{value: ev.newFunc(vs), created: ev.timestamp(), overflow: overflow}
+	ev.touch(e)
+
+	k := ev.marshaler.retain(ls)
+
+	if v, ok := ev.entities.LoadOrStore(k, e); ok {
+		ev.marshaler.release(k)
+
+		if !overflow {
+			atomic.AddInt64(&ev.size, -1)
+		}
+
+		e = v.(*entityEntry)
+		ev.touch(e)
+	}
+
+	return e
+}
+
+// Delete removes the series with the given label values, it returns false if
+// the series does not exist.
+func (ev *entityVector) Delete(ls ...string) bool {
+	ev.assertLabelCount(ls)
+
+	k, e, ok := ev.load(ls)
+
+	return ok && ev.deleteEntry(k, e)
+}
+
+// Reset removes all the series of the vector.
+func (ev *entityVector) Reset() {
+	ev.entities.Range(func(k, v interface{}) bool {
+		ev.deleteEntry(k.(uint64), v.(*entityEntry))
+		return true
+	})
+}
+
+func (ev *entityVector) deleteEntry(k uint64, e *entityEntry) bool {
+	if !ev.entities.CompareAndDelete(k, e) {
+		return false
+	}
+
+	ev.marshaler.release(k)
+
+	if !e.overflow {
+		atomic.AddInt64(&ev.size, -1)
+	}
+
+	return true
+}
+
+// rangeEntities calls fn with the tags and the value of every series, the
+// series not accessed within the ttl are deleted instead.
+func (ev *entityVector) rangeEntities(fn func(map[string]string, interface{})) {
+	var deadline int64
+
+	if ev.ttl > 0 {
+		deadline = ev.timestamp() - int64(ev.ttl)
+	}
+
+	ev.entities.Range(func(k, v interface{}) bool {
+		var (
+			key = k.(uint64)
+			e   = v.(*entityEntry)
+		)
+
+		if ev.ttl > 0 && atomic.LoadInt32(&e.pinned) == 0 &&
+			atomic.LoadInt64(&e.touched) < deadline {
+			ev.deleteEntry(key, e)
+			return true
+		}
+
+		vs, ok := ev.marshaler.unmarshal(key)
+
+		if !ok {
+			return true
+		}
+
+		tags := make(map[string]string, len(ev.labels))
+
+		for i, val := range vs {
+			tags[ev.labels[i]] = val
+		}
+
+		fn(tags, e.value)
+
+		return true
+	})
+}
+
+// Created returns the creation time of the series with the given tags, false
+// is returned if the series does not exist.
+func (ev *entityVector) Created(tags map[string]string) (time.Time, bool) {
+	var ls = make([]string, len(ev.labels))
+
+	for i, l := range ev.labels {
+		v, ok := tags[l]
+
+		if !ok {
+			return time.Time{}, false
+		}
+
+		ls[i] = v
+	}
+
+	_, e, ok := ev.load(ls)
+
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, e.created), true
+}
+
+// CreatedGetter is implemented by the vector getters recording the creation
+// time of their series, the collectors exposing it, such as the _created
+// samples of the OpenMetrics format, rely on it.
+type CreatedGetter interface {
+	Created(map[string]string) (time.Time, bool)
+}
+
+// SeriesCreated returns the creation time of the series of the getter with
+// the given tags, false is returned if g does not implement CreatedGetter or
+// if the series does not exist.
+func SeriesCreated(g interface{}, tags map[string]string) (time.Time, bool) {
+	if cg, ok := g.(CreatedGetter); ok {
+		return cg.Created(tags)
+	}
+
+	return time.Time{}, false
+}
+
+// Float64Value represents a single float64 metric value with its associated tags.
+type Float64Value struct {
+	Tags  map[string]string
+	Value float64
+}
+
+// Float64VectorGetter provides read access to float64 vectors for collectors.
+// Used by both float counters and float gauges.
+type Float64VectorGetter interface {
+	// Labels returns the label names for this vector.
+	Labels() []string
+
+	// Get returns all values with their label combinations.
+	Get() []*Float64Value
+}
+
+type atomicFloat64Vector struct {
+	entityVector
+}
+
+func newAtomicFloat64Vector(ls []string, lm labelMarshaler, mo metricOptions) *atomicFloat64Vector {
+	return &atomicFloat64Vector{
+		entityVector: entityVector{
+			labels:        ls,
+			marshaler:     lm,
+			newFunc:       func(map[string]string) interface{} { return &atomicFloat64{} },
+			now:           time.Now,
+			metricOptions: mo,
+		},
+	}
+}
+
+func (v *atomicFloat64Vector) Labels() []string   { return v.labels }
+func (v *atomicFloat64Vector) Metadata() Metadata { return v.metadata }
+
+func (v *atomicFloat64Vector) Get() []*Float64Value {
+	var res []*Float64Value
+
+	v.rangeEntities(func(tags map[string]string, e interface{}) {
+		res = append(res, &Float64Value{Tags: tags, Value: e.(*atomicFloat64).Get()})
+	})
+
+	return res
+}
+
+func (v *atomicFloat64Vector) fetchValue(ls []string) *atomicFloat64 {
+	return v.entity(ls).(*atomicFloat64)
 }
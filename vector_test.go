@@ -0,0 +1,308 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorDelete(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		mutate     func(Scope)
+		introspect func(*testing.T, Snapshot)
+	}{
+		{
+			name: "delete counter",
+			mutate: func(s Scope) {
+				cv := s.CounterVector("foo", []string{"bar"})
+
+				cv.WithLabels("buz").Inc()
+				cv.WithLabels("biz").Inc()
+
+				assert.True(t, cv.Delete("buz"))
+				assert.False(t, cv.Delete("buz"))
+				assert.False(t, cv.Delete("unknown"))
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{Name: "foo", Labels: map[string]string{"bar": "biz"}, Value: 1},
+					},
+				},
+			),
+		},
+		{
+			name: "delete and recreate gauge",
+			mutate: func(s Scope) {
+				gv := s.GaugeVector("foo", []string{"bar"})
+
+				gv.WithLabels("buz").Update(3)
+				gv.Delete("buz")
+				gv.WithLabels("buz").Inc()
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Gauges: []Int64Snapshot{
+						{Name: "foo", Labels: map[string]string{"bar": "buz"}, Value: 1},
+					},
+				},
+			),
+		},
+		{
+			name: "delete histogram from sub scope",
+			mutate: func(s Scope) {
+				hv := s.Scope("", map[string]string{"fiz": "baz"}).HistogramVector(
+					"foo",
+					[]string{"bar"},
+					StaticBuckets(nil),
+				)
+
+				hv.WithLabels("buz").Record(1)
+
+				assert.True(t, hv.Delete("buz"))
+			},
+			introspect: snapshotEqual(Snapshot{}),
+		},
+		{
+			name: "delete reordered counter",
+			mutate: func(s Scope) {
+				s.CounterVector("foo", []string{"a", "b"}).WithLabels("1", "2").Inc()
+
+				assert.True(t, s.CounterVector("foo", []string{"b", "a"}).Delete("2", "1"))
+			},
+			introspect: snapshotEqual(Snapshot{}),
+		},
+		{
+			name: "delete every incarnation",
+			mutate: func(s Scope) {
+				cv := LocalIncarnationScope(s, "incarnation").CounterVector(
+					"foo",
+					[]string{"bar"},
+				)
+
+				cv.WithLabels("buz").Inc()
+				cv.WithLabels("buz").Inc()
+				cv.WithLabels("biz").Inc()
+
+				assert.True(t, cv.Delete("buz"))
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"bar": "biz", "incarnation": "0"},
+							Value:  1,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "delete live incarnations",
+			mutate: func(s Scope) {
+				cv := LocalIncarnationScope(s, "incarnation").CounterVector(
+					"foo",
+					[]string{"bar"},
+				)
+
+				cv.Bind("buz").Inc()
+				cv.Bind("buz").Inc()
+
+				assert.True(t, cv.Delete("buz"))
+				assert.False(t, cv.Delete("buz"))
+
+				cv.Bind("buz").Add(3)
+			},
+			introspect: snapshotEqual(
+				Snapshot{
+					Counters: []Int64Snapshot{
+						{
+							Name:   "foo",
+							Labels: map[string]string{"bar": "buz", "incarnation": "2"},
+							Value:  3,
+						},
+					},
+				},
+			),
+		},
+		{
+			name: "reset",
+			mutate: func(s Scope) {
+				s.Scope("", map[string]string{"fiz": "baz"}).Counter("foo").Inc()
+
+				cv := s.CounterVector("foo", []string{"fiz"})
+
+				cv.WithLabels("buz").Inc()
+				cv.Reset()
+			},
+			introspect: snapshotEqual(Snapshot{}),
+		},
+		{
+			name: "noop",
+			mutate: func(Scope) {
+				assert.False(t, NoopScope.CounterVector("foo", nil).Delete())
+				NoopScope.HistogramVector("foo", nil).Reset()
+			},
+			introspect: snapshotEqual(Snapshot{}),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStaticCollector()
+
+			tt.mutate(RootScope(c))
+			tt.introspect(t, c.Get())
+		})
+	}
+}
+
+func TestVectorTTL(t *testing.T) {
+	var (
+		now = time.Unix(0, 0)
+
+		lm = newDefaultMarshaler()
+		v  = newAtomicInt64Vector(
+			[]string{"bar"},
+			lm,
+			buildMetricOptions([]MetricOption{WithTTL(time.Minute)}),
+		)
+		cv = counterVector{v}
+	)
+
+	v.now = func() time.Time { return now }
+
+	cv.WithLabels("buz").Inc()
+	cv.WithLabels("biz").Inc()
+
+	now = now.Add(45 * time.Second)
+	cv.WithLabels("biz").Inc()
+
+	assert.Len(t, v.Get(), 2)
+
+	now = now.Add(30 * time.Second)
+
+	assert.Equal(
+		t,
+		[]*Int64Value{{Tags: map[string]string{"bar": "biz"}, Value: 2}},
+		v.Get(),
+	)
+
+	_, ok := lm.marshal([]string{"buz"})
+	assert.False(t, ok)
+
+	now = now.Add(time.Hour)
+
+	assert.Empty(t, v.Get())
+	assert.Empty(t, lm.(*hashingMarshaler).keys)
+}
+
+func TestMarshalerSharedValues(t *testing.T) {
+	var (
+		lm = newDefaultMarshaler()
+		cv = counterVector{newAtomicInt64Vector([]string{"bar"}, lm, metricOptions{})}
+		gv = gaugeVector{newAtomicInt64Vector([]string{"bar"}, lm, metricOptions{})}
+	)
+
+	cv.WithLabels("buz").Inc()
+	gv.WithLabels("buz").Inc()
+
+	cv.Delete("buz")
+
+	assert.Equal(
+		t,
+		[]*Int64Value{{Tags: map[string]string{"bar": "buz"}, Value: 1}},
+		gv.Get(),
+	)
+
+	gv.Reset()
+
+	assert.Empty(t, lm.(*hashingMarshaler).keys)
+}
+
+func TestVectorMaxSeries(t *testing.T) {
+	var (
+		c  = NewStaticCollector()
+		s  = RootScope(c)
+		md = Metadata{
+			Help: "Number of series creations rejected by the maximum number of series",
+		}
+	)
+
+	cv := s.CounterVector("foo", []string{"bar", "biz"}, WithMaxSeries(2))
+	hv := s.HistogramVector("hist", []string{"bar"}, StaticBuckets(nil), WithMaxSeries(1))
+
+	cv.WithLabels("a", "1").Inc()
+	cv.WithLabels("b", "1").Inc()
+	cv.WithLabels("c", "1").Inc()
+	cv.WithLabels("d", "1").Add(2)
+	cv.WithLabels("a", "1").Inc()
+
+	hv.WithLabels("a").Record(1)
+	hv.WithLabels("b").Record(1)
+
+	sn := c.Get()
+
+	assert.Equal(
+		t,
+		[]Int64Snapshot{
+			{
+				Name:   "foo",
+				Labels: map[string]string{"bar": "__overflow__", "biz": "__overflow__"},
+				Value:  3,
+			},
+			{Name: "foo", Labels: map[string]string{"bar": "a", "biz": "1"}, Value: 2},
+			{Name: "foo", Labels: map[string]string{"bar": "b", "biz": "1"}, Value: 1},
+			{
+				Name:     CardinalityOverflowMetric,
+				Labels:   map[string]string{"metric": "foo"},
+				Value:    2,
+				Metadata: md,
+			},
+			{
+				Name:     CardinalityOverflowMetric,
+				Labels:   map[string]string{"metric": "hist"},
+				Value:    1,
+				Metadata: md,
+			},
+		},
+		sn.Counters,
+	)
+	assert.Len(t, sn.Histograms, 2)
+
+	cv.Delete("b", "1")
+	cv.WithLabels("e", "1").Inc()
+
+	assert.Len(t, c.Get().Counters, 5)
+	assert.Equal(t, int64(1), cv.WithLabels("e", "1").Get())
+}
+
+func TestVectorBind(t *testing.T) {
+	var (
+		now = time.Unix(0, 0)
+
+		s  = RootScope(NewStaticCollector())
+		cv = s.Scope("", map[string]string{"fiz": "biz"}).CounterVector(
+			"foo",
+			[]string{"bar"},
+			WithTTL(time.Minute),
+		)
+		v = s.rootScope().counters["foo"]
+	)
+
+	v.now = func() time.Time { return now }
+
+	bound := cv.Bind("buz")
+	cv.WithLabels("baz").Inc()
+
+	assert.Equal(t, 0., testing.AllocsPerRun(100, bound.Inc))
+
+	now = now.Add(time.Hour)
+
+	assert.Equal(
+		t,
+		[]*Int64Value{{Tags: map[string]string{"bar": "buz", "fiz": "biz"}, Value: 101}},
+		v.Get(),
+	)
+}